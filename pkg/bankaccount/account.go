@@ -1,9 +1,10 @@
 package bankaccount
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 	"sync"
+	"time"
 )
 
 // Note that this is purely for example purposes and is not production code quality. I wrote my own
@@ -12,79 +13,315 @@ import (
 type Account interface {
 	Balance() Money
 	BalanceAsCurrency(string) (Money, error)
-	Deposit(Money) error
-	Withdraw(Money) error
+	Deposit(Money, ...TransactionOption) error
+	Withdraw(Money, ...TransactionOption) error
+	Transactions(TransactionFilter) []Transaction
 	RemittanceAddress() string
 }
 
+// SavingsAccount is an Account that holds a balance in a single currency. It is safe for concurrent use.
 type SavingsAccount struct {
-	balance Money
-	sync.Mutex
+	mu       sync.RWMutex
+	balance  Money
+	rounding RoundingMode
+	rates    ExchangeRateProvider
+	pricing  Pricing
+	// autoConversion converts deposits and withdrawals in other currencies when it is not nil
+	autoConversion ExchangeRateProvider
+	ledger         ledger
+	journal        *Journal
+	customer       LedgerAccount
+	seq            uint64
+	idempotency    idempotencyKeys
+}
+
+// Conversion records how a deposit or withdrawal in another currency was converted to the account's currency.
+type Conversion struct {
+	// Original is the amount as it was deposited or withdrawn.
+	Original Money
+	// Rate is the rate it was converted at.
+	Rate Rate
+	// Converted is the amount credited to or debited from the balance.
+	Converted Money
 }
 
 type SavingsAccountOption func(*SavingsAccount)
 
+// WithBalance sets the opening balance of the account. NewSavingsAccount returns an error if it is not a valid
+// Money.
 func WithBalance(m Money) SavingsAccountOption {
 	return func(s *SavingsAccount) {
 		s.balance = m
 	}
 }
 
-func NewSavingsAccount(opts ...SavingsAccountOption) *SavingsAccount {
+// WithRoundingMode sets how fractional nanos are rounded when converting the balance to another currency.
+// By default, RoundHalfUp is used.
+func WithRoundingMode(mode RoundingMode) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.rounding = mode
+	}
+}
+
+// WithRateProvider sets where the account looks up exchange rates when converting the balance to another
+// currency. By default, CurrentRates is used.
+func WithRateProvider(rates ExchangeRateProvider) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.rates = rates
+	}
+}
+
+// WithPricing sets the spread and fees charged when quoting a conversion of the balance to another currency.
+// By default, conversions are quoted at the mid-market rate for free.
+func WithPricing(p Pricing) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.pricing = p
+	}
+}
+
+// WithAutoConversion converts deposits and withdrawals in other currencies to the account's currency at the
+// current rate from the given provider, instead of rejecting them. The converted amount is rounded to the minor
+// unit of the account's currency using the account's rounding mode, and the conversion is recorded; see
+// Conversions. Deposits and withdrawals are still rejected if the provider has no rate for them.
+func WithAutoConversion(rates ExchangeRateProvider) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.autoConversion = rates
+	}
+}
+
+// WithClock sets the clock used to timestamp transactions. By default, time.Now is used.
+func WithClock(now func() time.Time) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.ledger.now = now
+	}
+}
+
+// WithJournal posts every change to the balance to a double-entry journal, using the ledger account of the
+// customer with the given ID. A nonzero opening balance is posted as a deposit when the account is created.
+func WithJournal(j *Journal, customerID string) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		s.journal = j
+		s.customer = CustomerLedgerAccount(customerID)
+	}
+}
+
+// WithIdempotencyRetention sets how long the account remembers the idempotency keys of DepositIdempotent and
+// WithdrawIdempotent requests. By default, they are remembered for DefaultIdempotencyRetention. A retention that
+// is not positive would forget every key at once and silently apply retried requests again, so it is ignored.
+func WithIdempotencyRetention(retention time.Duration) SavingsAccountOption {
+	return func(s *SavingsAccount) {
+		if retention > 0 {
+			s.idempotency.retention = retention
+		}
+	}
+}
+
+// NewSavingsAccount opens an account with the given options. It returns an error if the opening balance is
+// invalid or cannot be posted to the account's journal.
+func NewSavingsAccount(opts ...SavingsAccountOption) (*SavingsAccount, error) {
 	m, _ := NewMoney(USD, 0, 0)
 	acct := &SavingsAccount{
 		balance: m,
+		rates:   CurrentRates,
+		seq:     nextAccountSeq(),
+		idempotency: idempotencyKeys{
+			retention: DefaultIdempotencyRetention,
+		},
 	}
 	for _, opt := range opts {
 		opt(acct)
 	}
-	return acct
+	if err := acct.balance.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid opening balance: %w", err)
+	}
+	if !acct.balance.IsZero() {
+		if err := postTo(acct.journal, "opening balance", depositPostings(acct.customer, acct.balance, nil)); err != nil {
+			return nil, fmt.Errorf("posting opening balance of %s: %w", acct.balance, err)
+		}
+		acct.ledger.append(nil, Transaction{Type: TransactionOpeningBalance, Amount: acct.balance, Balance: acct.balance})
+	}
+	return acct, nil
 }
 
 func (s *SavingsAccount) Balance() Money {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.balance
 }
 
+// BalanceAsCurrency values the balance in another currency at the mid-market rate, without any spread or fees.
+// Use QuoteBalanceAsCurrency for what the customer would receive by converting it.
 func (s *SavingsAccount) BalanceAsCurrency(currencyCode string) (Money, error) {
-	conversion := exchangeRate{s.balance.CurrencyCode, currencyCode}
-	rate, found := CurrentRates.rates[conversion]
-	if !found {
-		return Money{}, fmt.Errorf("currency code not found in current exchange tables")
+	balance := s.Balance()
+	rate, err := s.rates.Rate(context.Background(), balance.CurrencyCode, currencyCode)
+	if err != nil {
+		return Money{}, err
 	}
-	mantissa, exponent := asExponent(rate.Units, rate.Nanos)
-	m := s.balance.Multiply(int(mantissa), exponent)
-	m.CurrencyCode = currencyCode
+	return rate.Convert(balance, s.rounding)
+}
 
-	return m, nil
+// QuoteBalanceAsCurrency quotes converting the whole balance to another currency using the account's pricing.
+func (s *SavingsAccount) QuoteBalanceAsCurrency(currencyCode string) (ConversionQuote, error) {
+	balance := s.Balance()
+	rate, err := s.rates.Rate(context.Background(), balance.CurrencyCode, currencyCode)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	return s.pricing.Quote(rate, balance, s.rounding)
 }
 
-func asExponent(units int64, nanos int32) (int, int) {
-	i, _ := strconv.Atoi(fmt.Sprintf("%d%09d", units, nanos))
-	return i, -9
+// BalanceAsCurrencyAt converts the balance the account had at time t using the exchange rate that was in effect
+// then, e.g., to reproduce a past statement. The balance is the one left by the last transaction at or before t,
+// or the opening balance if there was none. The account's rate provider must be a HistoricalRateProvider.
+func (s *SavingsAccount) BalanceAsCurrencyAt(currencyCode string, t time.Time) (Money, error) {
+	s.mu.RLock()
+	balance := s.balanceAt(t)
+	s.mu.RUnlock()
+	history, ok := s.rates.(HistoricalRateProvider)
+	if !ok {
+		return Money{}, fmt.Errorf("the account's exchange rate provider does not keep historical rates")
+	}
+	rate, err := history.RateAsOf(context.Background(), balance.CurrencyCode, currencyCode, t)
+	if err != nil {
+		return Money{}, err
+	}
+	return rate.Convert(balance, s.rounding)
+}
+
+// balanceAt returns the balance left by the last transaction at or before time t, or the opening balance if
+// there was none. The caller must hold the lock.
+func (s *SavingsAccount) balanceAt(t time.Time) Money {
+	balance := Money{CurrencyCode: s.balance.CurrencyCode}
+	for i, transaction := range s.ledger.transactions {
+		if (i == 0 && transaction.Type == TransactionOpeningBalance) || !transaction.Timestamp.After(t) {
+			balance = transaction.Balance
+		}
+	}
+	return balance
 }
 
-func (s *SavingsAccount) Deposit(m Money) error {
-	s.Lock()
+// Transactions returns the transactions the filter selects from the account's ledger, oldest first.
+func (s *SavingsAccount) Transactions(filter TransactionFilter) []Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ledger.query(filter)
+}
+
+// Conversions returns the conversions made by auto conversion, oldest first.
+func (s *SavingsAccount) Conversions() []Conversion {
+	conversions := []Conversion{}
+	for _, t := range s.Transactions(TransactionFilter{}) {
+		if t.Conversion != nil {
+			conversions = append(conversions, *t.Conversion)
+		}
+	}
+	return conversions
+}
+
+// convert converts m to the account's currency if it is in another currency and auto conversion is enabled.
+// Otherwise, m is returned as it is and the conversion is nil.
+func (s *SavingsAccount) convert(m Money) (Money, *Conversion, error) {
+	currencyCode := s.Balance().CurrencyCode
+	if s.autoConversion == nil || m.CurrencyCode == currencyCode {
+		return m, nil, nil
+	}
+	rate, err := s.autoConversion.Rate(context.Background(), m.CurrencyCode, currencyCode)
+	if err != nil {
+		return Money{}, nil, fmt.Errorf("cannot convert %s to %s: %w", m, currencyCode, err)
+	}
+	converted, err := rate.convertToMinorUnits(m, s.rounding)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	return converted, &Conversion{Original: m, Rate: rate, Converted: converted}, nil
+}
+
+// Deposit credits m to the balance and records the transaction in the account's ledger.
+func (s *SavingsAccount) Deposit(m Money, opts ...TransactionOption) error {
+	m, conversion, err := s.convert(m)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.deposit(m, conversion, opts)
+	return err
+}
+
+// deposit adds m, which is in the account's currency, to the balance, posts it to the journal and records it in
+// the ledger. The caller must hold the lock.
+func (s *SavingsAccount) deposit(m Money, conversion *Conversion, opts []TransactionOption) (Transaction, error) {
 	newBalance, err := s.balance.Add(m)
-	if err == nil {
-		s.balance = newBalance
+	if err != nil {
+		return Transaction{}, err
+	}
+	if err := postTo(s.journal, fmt.Sprintf("deposit of %s", m), depositPostings(s.customer, m, conversion)); err != nil {
+		return Transaction{}, err
+	}
+	s.balance = newBalance
+	return s.ledger.append(opts, Transaction{Type: TransactionDeposit, Amount: m, Balance: newBalance, Conversion: conversion})[0], nil
+}
+
+// Withdraw debits m from the balance, unless that would overdraw it, and records the transaction in the
+// account's ledger.
+func (s *SavingsAccount) Withdraw(m Money, opts ...TransactionOption) error {
+	m, conversion, err := s.convert(m)
+	if err != nil {
+		return err
 	}
-	s.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.withdraw(m, conversion, opts)
 	return err
 }
 
-func (s *SavingsAccount) Withdraw(m Money) error {
-	s.Lock()
-	newBalance, err := s.balance.Subtract(m)
-	if newBalance.IsNegative() {
-		err = fmt.Errorf("withdrawal of %s would overdraw from balance of %s", m, s.balance)
-	} else if err == nil {
-		s.balance = newBalance
+// withdraw subtracts m, which is in the account's currency, from the balance, unless that would overdraw it,
+// posts it to the journal and records it in the ledger. The caller must hold the lock.
+func (s *SavingsAccount) withdraw(m Money, conversion *Conversion, opts []TransactionOption) (Transaction, error) {
+	return s.debit(Transaction{Type: TransactionWithdrawal, Amount: m, Conversion: conversion},
+		withdrawalPostings(s.customer, m, conversion), opts)
+}
+
+// ChargeFee debits a fee charged by the bank from the balance, unless that would overdraw it, and records the
+// transaction in the account's ledger. The fee must be positive and in the account's currency.
+func (s *SavingsAccount) ChargeFee(fee Money, opts ...TransactionOption) error {
+	if err := checkFee(fee); err != nil {
+		return err
 	}
-	s.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.debit(Transaction{Type: TransactionFee, Amount: fee}, feePostings(s.customer, fee), opts)
 	return err
 }
 
+// debit subtracts the amount of the transaction from the balance, unless that would overdraw it, posts it to the
+// journal and records it in the ledger. The caller must hold the lock.
+func (s *SavingsAccount) debit(t Transaction, postings []Posting, opts []TransactionOption) (Transaction, error) {
+	newBalance, err := s.debitedBalance(t)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if err := postTo(s.journal, fmt.Sprintf("%s of %s", t.Type, t.Amount), postings); err != nil {
+		return Transaction{}, err
+	}
+	s.balance = newBalance
+	t.Balance = newBalance
+	return s.ledger.append(opts, t)[0], nil
+}
+
+// debitedBalance returns what the balance would be after debiting the amount of the transaction, without
+// changing it. The caller must hold the lock.
+func (s *SavingsAccount) debitedBalance(t Transaction) (Money, error) {
+	overdrawn, err := s.balance.LessThan(t.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	if overdrawn {
+		return Money{}, &InsufficientFundsError{Operation: t.Type.String(), Requested: t.Amount, Available: s.balance}
+	}
+	return s.balance.Subtract(t.Amount)
+}
+
 func (s *SavingsAccount) RemittanceAddress() string {
 	return "742 Evergreen Terrace\nSpringfield, OR"
 }
@@ -0,0 +1,84 @@
+package bankaccount
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// mustNewSavingsAccount returns a new SavingsAccount, failing the test if it cannot be opened.
+func mustNewSavingsAccount(t *testing.T, opts ...SavingsAccountOption) *SavingsAccount {
+	t.Helper()
+	acct, err := NewSavingsAccount(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acct
+}
+
+func TestInvalidOpeningBalance(t *testing.T) {
+	is := is.New(t)
+	j := NewJournal()
+	for _, opts := range [][]SavingsAccountOption{
+		{WithBalance(Money{USD, 1, -1})},
+		{WithBalance(Money{USD, 1, -1}), WithJournal(j, "alice")},
+		{WithBalance(Money{"XYZ", 1, 0}), WithJournal(j, "alice")},
+	} {
+		acct, err := NewSavingsAccount(opts...)
+		is.True(errors.Is(err, ErrInvalidMoney)) // rejected with or without a journal
+		is.True(acct == nil)
+	}
+	is.Equal(len(j.Entries()), 0) // nothing was posted
+}
+
+// TestConcurrentReadsAndWrites mixes reads of the balance with deposits and withdrawals. Run it with -race to
+// check that every read of the account is synchronized.
+func TestConcurrentReadsAndWrites(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}), WithJournal(NewJournal(), "1"))
+	const writers, readers, rounds = 8, 8, 100
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < rounds; n++ {
+				acct.Deposit(Money{USD, 1, 0})
+				acct.Withdraw(Money{USD, 1, 0})
+			}
+		}()
+	}
+	readErrs := make(chan error, readers)
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < rounds; n++ {
+				if err := acct.Balance().Validate(); err != nil {
+					readErrs <- err
+					return
+				}
+				if _, err := acct.BalanceAsCurrency(CAD); err != nil {
+					readErrs <- err
+					return
+				}
+				if _, err := acct.QuoteBalanceAsCurrency(EUR); err != nil {
+					readErrs <- err
+					return
+				}
+				acct.Transactions(TransactionFilter{Types: []TransactionType{TransactionDeposit}})
+			}
+		}()
+	}
+	wg.Wait()
+	close(readErrs)
+
+	for err := range readErrs {
+		is.NoErr(err)
+	}
+	is.Equal(acct.Balance(), Money{USD, 100, 0})
+	is.Equal(len(acct.Transactions(TransactionFilter{})), writers*rounds*2+1) // and the opening balance
+}
@@ -2,41 +2,134 @@ package bankaccount_test
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/cucumber/godog"
 	. "github.com/dumpsterfireproject/godog-examples/pkg/bankaccount"
+	"github.com/dumpsterfireproject/godog-examples/pkg/bankaccount/ratefeedtest"
 )
 
 type AccountTestState struct {
 	account   Account
+	rates     ExchangeRateProvider
+	feed      *ratefeedtest.Server
 	lastError error
 }
 
 func (a *AccountTestState) reset() {
 	a.account = nil
+	a.rates = nil
+	if a.feed != nil {
+		a.feed.Close()
+	}
+	a.feed = nil
 	a.lastError = nil
 }
 
+// newAccount opens the account under test using any exchange rates given for the scenario.
+func (a *AccountTestState) newAccount(opts ...SavingsAccountOption) error {
+	if a.rates != nil {
+		opts = append(opts, WithRateProvider(a.rates))
+	}
+	acct, err := NewSavingsAccount(opts...)
+	if err != nil {
+		return err
+	}
+	a.account = acct
+	return nil
+}
+
 // Arrange steps
-func (a *AccountTestState) iHaveANewAccount() {
-	a.account = NewSavingsAccount()
+func (a *AccountTestState) iHaveANewAccount() error {
+	return a.newAccount()
 }
 
-func (a *AccountTestState) iHaveAnAccountWith(units int, nanos int, currency string) error {
-	m, err := NewMoney(currency, int64(units), convertToNanos(nanos))
-	a.account = NewSavingsAccount(WithBalance(m))
-	return err
+func (a *AccountTestState) iHaveAnAccountWith(amount string) error {
+	m, err := ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+	return a.newAccount(WithBalance(m))
+}
+
+func (a *AccountTestState) iHaveAnAccountThatConvertsOtherCurrencies(amount string) error {
+	m, err := ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+	rates := a.rates
+	if rates == nil {
+		rates = CurrentRates
+	}
+	return a.newAccount(WithBalance(m), WithAutoConversion(rates))
+}
+
+func (a *AccountTestState) iHaveAMultiCurrencyAccountReportingIn(currency string) error {
+	opts := []MultiCurrencyAccountOption{WithReportingCurrency(currency)}
+	if a.rates != nil {
+		opts = append(opts, WithMultiCurrencyRateProvider(a.rates))
+	}
+	acct, err := NewMultiCurrencyAccount(opts...)
+	if err != nil {
+		return err
+	}
+	a.account = acct
+	return nil
+}
+
+func (a *AccountTestState) theFollowingExchangeRates(table *godog.Table) error {
+	rates := []Rate{}
+	// first row is header row, so skip it
+	for n, row := range table.Rows {
+		if n > 0 {
+			if len(row.Cells) < 3 {
+				return fmt.Errorf("too few columns")
+			}
+			rate, err := NewRate(row.Cells[0].Value, row.Cells[1].Value, row.Cells[2].Value)
+			if err != nil {
+				return err
+			}
+			rates = append(rates, rate)
+		}
+	}
+	a.rates = NewExchangeRates(rates...)
+	return nil
+}
+
+func (a *AccountTestState) theExchangeRatesIn(path string) error {
+	rates, err := LoadRatesFile(path)
+	if err != nil {
+		return err
+	}
+	a.rates = rates
+	return nil
+}
+
+func (a *AccountTestState) theECBPublishesTheFollowingRates(day string, table *godog.Table) error {
+	rates := map[string]string{}
+	// first row is header row, so skip it
+	for n, row := range table.Rows {
+		if n > 0 {
+			if len(row.Cells) < 2 {
+				return fmt.Errorf("too few columns")
+			}
+			rates[row.Cells[0].Value] = row.Cells[1].Value
+		}
+	}
+	a.feed = ratefeedtest.NewServer()
+	a.feed.ServeECB(day, rates)
+	a.rates = NewHTTPRateProvider(a.feed.URL, ParseECBFeed)
+	return nil
 }
 
 // Act steps
-func (a *AccountTestState) iDeposit(units int, nanos int, currency string) error {
-	m, err := NewMoney(currency, int64(units), convertToNanos(nanos))
+func (a *AccountTestState) iDeposit(amount string) error {
+	m, err := ParseMoney(amount)
 	if err != nil {
 		return err
 	}
@@ -44,8 +137,18 @@ func (a *AccountTestState) iDeposit(units int, nanos int, currency string) error
 	return err
 }
 
-func (a *AccountTestState) iWithdraw(units int, nanos int, currency string) error {
-	m, err := NewMoney(currency, int64(units), convertToNanos(nanos))
+func (a *AccountTestState) iTryToDeposit(amount string) error {
+	m, err := ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+	// this step does not fail if the deposit fails; it just stores the error for later validation
+	a.lastError = a.account.Deposit(m)
+	return nil
+}
+
+func (a *AccountTestState) iWithdraw(amount string) error {
+	m, err := ParseMoney(amount)
 	if err != nil {
 		return err
 	}
@@ -53,8 +156,8 @@ func (a *AccountTestState) iWithdraw(units int, nanos int, currency string) erro
 	return err
 }
 
-func (a *AccountTestState) iTryToWithdraw(units int, nanos int, currency string) error {
-	m, err := NewMoney(currency, int64(units), convertToNanos(nanos))
+func (a *AccountTestState) iTryToWithdraw(amount string) error {
+	m, err := ParseMoney(amount)
 	if err != nil {
 		return err
 	}
@@ -64,6 +167,25 @@ func (a *AccountTestState) iTryToWithdraw(units int, nanos int, currency string)
 	return nil
 }
 
+func (a *AccountTestState) iConvertTo(amount string, currency string) error {
+	m, err := ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+	acct, ok := a.account.(*MultiCurrencyAccount)
+	if !ok {
+		return fmt.Errorf("the account does not hold more than one currency")
+	}
+	_, err = acct.Convert(m.CurrencyCode, currency, m)
+	return err
+}
+
+func (a *AccountTestState) iTryToConvertTo(amount string, currency string) error {
+	// this step does not fail if the conversion fails; it just stores the error for later validation
+	a.lastError = a.iConvertTo(amount, currency)
+	return nil
+}
+
 type transaction struct {
 	isWithdrawal bool
 	money        Money
@@ -77,11 +199,7 @@ func (a *AccountTestState) iProcessTheFollowingTransations(table *godog.Table) e
 			if len(row.Cells) < 2 {
 				return fmt.Errorf("too few columns")
 			}
-			units, err := strconv.Atoi(row.Cells[1].Value)
-			if err != nil {
-				return err
-			}
-			money, err := NewMoney(USD, int64(units), 0)
+			money, err := ParseMoney(row.Cells[1].Value + " " + USD)
 			if err != nil {
 				return err
 			}
@@ -107,72 +225,144 @@ func (a *AccountTestState) iProcessTheFollowingTransations(table *godog.Table) e
 }
 
 // Assert steps
-func (a *AccountTestState) theAccountBalanceIs(units int, nanos int, currency string) error {
+func (a *AccountTestState) theAccountBalanceIs(amount string) error {
 	acct := a.account
-	m, _ := NewMoney(currency, int64(units), convertToNanos(nanos))
+	m, err := ParseMoney(amount)
+	if err != nil {
+		return err
+	}
 	if !acct.Balance().IsEqual(m) {
 		return fmt.Errorf("expected the account balance to be %s by found %s", m, acct.Balance())
 	}
 	return nil
 }
 
-func (a *AccountTestState) theTransactionShouldError() error {
-	if a.lastError == nil {
-		return fmt.Errorf("the expected error was not found")
+func (a *AccountTestState) theAccountMustHold(table *godog.Table) error {
+	acct, ok := a.account.(*MultiCurrencyAccount)
+	if !ok {
+		return fmt.Errorf("the account does not hold more than one currency")
+	}
+	expected := []Money{}
+	// first row is header row, so skip it
+	for n, row := range table.Rows {
+		if n > 0 {
+			m, err := ParseMoney(row.Cells[0].Value)
+			if err != nil {
+				return err
+			}
+			expected = append(expected, m)
+		}
+	}
+	actual := acct.Balances()
+	if len(actual) != len(expected) {
+		return fmt.Errorf("expected the account to hold %v but found %v", expected, actual)
+	}
+	for i := range expected {
+		if !actual[i].IsEqual(expected[i]) {
+			return fmt.Errorf("expected the account to hold %v but found %v", expected, actual)
+		}
 	}
 	return nil
 }
 
-func (a *AccountTestState) theAccountBalanceMustConvertToUSD(input string) error {
-	tokens := strings.Split(input, ".")
-	if len(tokens) > 2 {
-		return fmt.Errorf("invalid format for dollars")
+func (a *AccountTestState) theLastConversionMustUseTheRate(rate string) error {
+	acct, ok := a.account.(*SavingsAccount)
+	if !ok {
+		return fmt.Errorf("the account does not convert other currencies")
 	}
-	units, err := strconv.Atoi(tokens[0])
-	if err != nil {
-		return err
+	conversions := acct.Conversions()
+	if len(conversions) == 0 {
+		return fmt.Errorf("expected a conversion at %s but found none", rate)
+	}
+	if actual := conversions[len(conversions)-1].Rate.String(); actual != rate {
+		return fmt.Errorf("expected the last conversion to use %s but found %s", rate, actual)
 	}
-	nanos := 0
-	if len(tokens) > 1 {
-		nanos, err = strconv.Atoi(tokens[1])
+	return nil
+}
+
+func (a *AccountTestState) theAccountMustHaveTheFollowingTransactions(table *godog.Table) error {
+	transactions := a.account.Transactions(TransactionFilter{})
+	if len(transactions) != len(table.Rows)-1 {
+		return fmt.Errorf("expected %d transactions but found %d", len(table.Rows)-1, len(transactions))
+	}
+	// first row is header row, so skip it
+	for n, row := range table.Rows[1:] {
+		if len(row.Cells) < 3 {
+			return fmt.Errorf("too few columns")
+		}
+		amount, err := ParseMoney(row.Cells[1].Value)
 		if err != nil {
 			return err
 		}
+		balance, err := ParseMoney(row.Cells[2].Value)
+		if err != nil {
+			return err
+		}
+		t := transactions[n]
+		if t.Type.String() != row.Cells[0].Value || !t.Amount.IsEqual(amount) || !t.Balance.IsEqual(balance) {
+			return fmt.Errorf("expected transaction %d to be a %s of %s leaving %s but found a %s of %s leaving %s",
+				n+1, row.Cells[0].Value, amount, balance, t.Type, t.Amount, t.Balance)
+		}
 	}
+	return nil
+}
 
-	expectedDollars, _ := NewMoney(USD, int64(units), convertToNanos(nanos))
-	actualDollars, err := a.account.BalanceAsCurrency(USD)
+func (a *AccountTestState) theTransactionShouldError() error {
+	if a.lastError == nil {
+		return fmt.Errorf("the expected error was not found")
+	}
+	return nil
+}
+
+// transactionErrors maps the failures named in the feature files to the errors they match.
+var transactionErrors = map[string]error{
+	"insufficient funds":  ErrInsufficientFunds,
+	"a currency mismatch": ErrCurrencyMismatch,
+	"no exchange rate":    ErrRateNotFound,
+	"invalid money":       ErrInvalidMoney,
+}
+
+func (a *AccountTestState) theTransactionShouldFailWith(failure string) error {
+	if a.lastError == nil {
+		return fmt.Errorf("the expected error was not found")
+	}
+	if !errors.Is(a.lastError, transactionErrors[failure]) {
+		return fmt.Errorf("expected the transaction to fail with %s but found: %v", failure, a.lastError)
+	}
+	return nil
+}
+
+func (a *AccountTestState) theAccountBalanceMustConvertTo(input string) error {
+	expected, err := ParseMoney(input)
+	if err != nil {
+		return err
+	}
+	actual, err := a.account.BalanceAsCurrency(expected.CurrencyCode)
 	if err != nil {
 		return err
 	}
-	if !actualDollars.IsEqual(expectedDollars) {
-		return fmt.Errorf("expected the account balance to be %s but found %s", expectedDollars, actualDollars)
+	if !actual.IsEqual(expected) {
+		return fmt.Errorf("expected the account balance to be %s but found %s", expected, actual)
 	}
 	return nil
 }
 
-func (a *AccountTestState) theRemittanceAddressMustBe(input *godog.DocString) error {
-	if a.account.RemittanceAddress() != input.Content {
-		return fmt.Errorf("expected %s but found %s", input.Content, a.account.RemittanceAddress())
+func (a *AccountTestState) theAccountBalanceMustNotConvertTo(currency string) error {
+	m, err := a.account.BalanceAsCurrency(currency)
+	if err == nil {
+		return fmt.Errorf("expected the account balance not to convert to %s but found %s", currency, m)
+	}
+	if !errors.Is(err, ErrRateNotFound) {
+		return fmt.Errorf("expected no exchange rate to %s but found: %v", currency, err)
 	}
 	return nil
 }
 
-// helper functions
-
-// if the step has something like 1.25, the 25 is really 250000000 nanos
-// this function handles that
-func convertToNanos(i int) int32 {
-	multiplier := 100000000
-	remainingDigits := i
-	for {
-		remainingDigits = remainingDigits / 10
-		if remainingDigits == 0 {
-			break
-		}
-		multiplier = multiplier / 10
+func (a *AccountTestState) theRemittanceAddressMustBe(input *godog.DocString) error {
+	if a.account.RemittanceAddress() != input.Content {
+		return fmt.Errorf("expected %s but found %s", input.Content, a.account.RemittanceAddress())
 	}
-	return int32(i * multiplier)
+	return nil
 }
 
 func InitializeScenario(sc *godog.ScenarioContext) {
@@ -182,18 +372,32 @@ func InitializeScenario(sc *godog.ScenarioContext) {
 		return ctx, nil
 	})
 	sc.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		ts.reset() // shut down any stub feed server
 		return ctx, nil
 	})
 	// Add step definitions here.
 	sc.Step(`^I have a new account$`, ts.iHaveANewAccount)
-	sc.Step(`^I have an account with (\d+)\.(\d+) ([A-Z]{3})$`, ts.iHaveAnAccountWith)
-	sc.Step(`^I deposit (\d+)\.(\d+) ([A-Z]{3})$`, ts.iDeposit)
-	sc.Step(`^I withdraw (\d+)\.(\d+) ([A-Z]{3})$`, ts.iWithdraw)
-	sc.Step(`^I try to withdraw (\d+)\.(\d+) ([A-Z]{3})$`, ts.iTryToWithdraw)
+	sc.Step(`^I have an account with (.+) that converts other currencies$`, ts.iHaveAnAccountThatConvertsOtherCurrencies)
+	sc.Step(`^I have an account with (.+)$`, ts.iHaveAnAccountWith)
+	sc.Step(`^I have a multi-currency account reporting in ([A-Z]{3})$`, ts.iHaveAMultiCurrencyAccountReportingIn)
+	sc.Step(`^the following exchange rates:$`, ts.theFollowingExchangeRates)
+	sc.Step(`^the exchange rates in "([^"]*)"$`, ts.theExchangeRatesIn)
+	sc.Step(`^the ECB publishes the following rates on (\d{4}-\d{2}-\d{2}):$`, ts.theECBPublishesTheFollowingRates)
+	sc.Step(`^I deposit (.+)$`, ts.iDeposit)
+	sc.Step(`^I try to deposit (.+)$`, ts.iTryToDeposit)
+	sc.Step(`^I withdraw (.+)$`, ts.iWithdraw)
+	sc.Step(`^I try to withdraw (.+)$`, ts.iTryToWithdraw)
+	sc.Step(`^I convert (.+) to ([A-Z]{3})$`, ts.iConvertTo)
+	sc.Step(`^I try to convert (.+) to ([A-Z]{3})$`, ts.iTryToConvertTo)
 	sc.Step(`^I process the following transations:$`, ts.iProcessTheFollowingTransations)
-	sc.Step(`^the account balance must be (\d+)\.(\d+) ([A-Z]{3})$`, ts.theAccountBalanceIs)
+	sc.Step(`^the account balance must be (.+)$`, ts.theAccountBalanceIs)
+	sc.Step(`^the account must hold:$`, ts.theAccountMustHold)
+	sc.Step(`^the last conversion must use the rate (.+)$`, ts.theLastConversionMustUseTheRate)
+	sc.Step(`^the account must have the following transactions:$`, ts.theAccountMustHaveTheFollowingTransactions)
 	sc.Step(`^the transaction should error$`, ts.theTransactionShouldError)
-	sc.Step(`^the account balance must convert to (\d+) USD$`, ts.theAccountBalanceMustConvertToUSD)
+	sc.Step(`^the transaction should fail with (insufficient funds|a currency mismatch|no exchange rate|invalid money)$`, ts.theTransactionShouldFailWith)
+	sc.Step(`^the account balance must convert to (.+)$`, ts.theAccountBalanceMustConvertTo)
+	sc.Step(`^the account balance must not convert to ([A-Z]{3})$`, ts.theAccountBalanceMustNotConvertTo)
 	sc.Step(`^the remittance address must be$`, ts.theRemittanceAddressMustBe)
 }
 
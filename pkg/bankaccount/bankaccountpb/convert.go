@@ -0,0 +1,115 @@
+package bankaccountpb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dumpsterfireproject/godog-examples/pkg/bankaccount"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromMoney converts a bankaccount.Money into its protobuf message.
+func FromMoney(m bankaccount.Money) *Money {
+	return &Money{
+		CurrencyCode: m.CurrencyCode,
+		Units:        m.Units,
+		Nanos:        m.Nanos,
+	}
+}
+
+// ToMoney converts the protobuf message into a bankaccount.Money, returning an error if the message does not
+// hold a valid amount. A nil message is an error, since there is no sensible default currency.
+func (x *Money) ToMoney() (bankaccount.Money, error) {
+	if x == nil {
+		return bankaccount.Money{}, fmt.Errorf("money is required")
+	}
+	return bankaccount.NewMoney(x.GetCurrencyCode(), x.GetUnits(), x.GetNanos())
+}
+
+// FromAccount converts a bankaccount.Account into its protobuf message.
+func FromAccount(id string, a bankaccount.Account) *Account {
+	return &Account{
+		Id:                id,
+		Balance:           FromMoney(a.Balance()),
+		RemittanceAddress: a.RemittanceAddress(),
+	}
+}
+
+var transactionTypes = map[bankaccount.TransactionType]TransactionType{
+	bankaccount.TransactionDeposit:        TransactionType_TRANSACTION_TYPE_DEPOSIT,
+	bankaccount.TransactionWithdrawal:     TransactionType_TRANSACTION_TYPE_WITHDRAWAL,
+	bankaccount.TransactionFee:            TransactionType_TRANSACTION_TYPE_FEE,
+	bankaccount.TransactionTransferOut:    TransactionType_TRANSACTION_TYPE_TRANSFER_OUT,
+	bankaccount.TransactionTransferIn:     TransactionType_TRANSACTION_TYPE_TRANSFER_IN,
+	bankaccount.TransactionOpeningBalance: TransactionType_TRANSACTION_TYPE_OPENING_BALANCE,
+}
+
+// FromTransactionType converts a bankaccount.TransactionType into its protobuf enum, or
+// TRANSACTION_TYPE_UNSPECIFIED if it has none.
+func FromTransactionType(t bankaccount.TransactionType) TransactionType {
+	return transactionTypes[t]
+}
+
+// ToTransactionType converts the protobuf enum into a bankaccount.TransactionType, returning an error if it
+// is unspecified or unknown.
+func (x TransactionType) ToTransactionType() (bankaccount.TransactionType, error) {
+	for t, pb := range transactionTypes {
+		if pb == x {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown transaction type %s", x)
+}
+
+// FromTransaction converts a bankaccount.Transaction on the given account into its protobuf message. The
+// message does not record currency conversions, so the Conversion of the transaction is dropped.
+func FromTransaction(accountID string, t bankaccount.Transaction) *Transaction {
+	x := &Transaction{
+		AccountId: accountID,
+		Id:        t.ID,
+		Type:      FromTransactionType(t.Type),
+		Amount:    FromMoney(t.Amount),
+		Balance:   FromMoney(t.Balance),
+		Memo:      t.Memo,
+	}
+	if !t.Timestamp.IsZero() {
+		x.Timestamp = timestamppb.New(t.Timestamp)
+	}
+	return x
+}
+
+// ToTransaction converts the protobuf message into a bankaccount.Transaction, returning an error if the
+// message does not hold a valid type, amount, balance, and timestamp. A missing timestamp leaves the
+// Timestamp of the transaction zero, and a present one is converted to UTC.
+func (x *Transaction) ToTransaction() (bankaccount.Transaction, error) {
+	if x == nil {
+		return bankaccount.Transaction{}, fmt.Errorf("transaction is required")
+	}
+	transactionType, err := x.GetType().ToTransactionType()
+	if err != nil {
+		return bankaccount.Transaction{}, err
+	}
+	amount, err := x.GetAmount().ToMoney()
+	if err != nil {
+		return bankaccount.Transaction{}, fmt.Errorf("invalid amount: %w", err)
+	}
+	balance, err := x.GetBalance().ToMoney()
+	if err != nil {
+		return bankaccount.Transaction{}, fmt.Errorf("invalid balance: %w", err)
+	}
+	var timestamp time.Time
+	if x.Timestamp != nil {
+		if err := x.Timestamp.CheckValid(); err != nil {
+			return bankaccount.Transaction{}, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		timestamp = x.Timestamp.AsTime()
+	}
+	return bankaccount.Transaction{
+		ID:        x.GetId(),
+		Type:      transactionType,
+		Amount:    amount,
+		Balance:   balance,
+		Timestamp: timestamp,
+		Memo:      x.GetMemo(),
+	}, nil
+}
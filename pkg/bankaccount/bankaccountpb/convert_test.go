@@ -0,0 +1,166 @@
+package bankaccountpb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dumpsterfireproject/godog-examples/pkg/bankaccount"
+	"github.com/matryer/is"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMoneyRoundTrip(t *testing.T) {
+	testCases := []bankaccount.Money{
+		{CurrencyCode: bankaccount.USD, Units: 0, Nanos: 0},
+		{CurrencyCode: bankaccount.USD, Units: 1, Nanos: 50000000},
+		{CurrencyCode: bankaccount.EUR, Units: -1, Nanos: -750000000},
+		{CurrencyCode: bankaccount.CAD, Units: 0, Nanos: -1},
+		{CurrencyCode: "JPY", Units: 9223372036854775807, Nanos: 999999999},
+	}
+	for _, m := range testCases {
+		t.Run(m.String(), func(t *testing.T) {
+			is := is.New(t)
+			data, err := proto.Marshal(FromMoney(m))
+			is.NoErr(err)
+			var decoded Money
+			is.NoErr(proto.Unmarshal(data, &decoded))
+			actual, err := decoded.ToMoney()
+			is.NoErr(err)
+			is.Equal(actual, m)
+		})
+	}
+}
+
+// The wire format of google.type.Money is currency_code = 1 (string), units = 2 (int64), nanos = 3 (int32).
+func TestMoneyIsWireCompatibleWithGoogleTypeMoney(t *testing.T) {
+	is := is.New(t)
+	units, nanos := int64(-1), int64(-750000000)
+	var googleMoney []byte
+	googleMoney = protowire.AppendTag(googleMoney, 1, protowire.BytesType)
+	googleMoney = protowire.AppendString(googleMoney, "USD")
+	googleMoney = protowire.AppendTag(googleMoney, 2, protowire.VarintType)
+	googleMoney = protowire.AppendVarint(googleMoney, uint64(units))
+	googleMoney = protowire.AppendTag(googleMoney, 3, protowire.VarintType)
+	googleMoney = protowire.AppendVarint(googleMoney, uint64(nanos))
+
+	data, err := proto.Marshal(FromMoney(bankaccount.Money{CurrencyCode: bankaccount.USD, Units: -1, Nanos: -750000000}))
+	is.NoErr(err)
+	is.Equal(data, googleMoney)
+
+	var decoded Money
+	is.NoErr(proto.Unmarshal(googleMoney, &decoded))
+	is.Equal(decoded.GetCurrencyCode(), "USD")
+	is.Equal(decoded.GetUnits(), int64(-1))
+	is.Equal(decoded.GetNanos(), int32(-750000000))
+}
+
+func TestToMoneyErrors(t *testing.T) {
+	testCases := map[string]*Money{
+		"nil":              nil,
+		"unknown currency": {CurrencyCode: "XYZ", Units: 1},
+		"mixed signs":      {CurrencyCode: "USD", Units: 1, Nanos: -1},
+		"too many nanos":   {CurrencyCode: "USD", Nanos: 1000000000},
+	}
+	for name, m := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := m.ToMoney(); err == nil {
+				t.Errorf("expected an error converting %v", m)
+			}
+		})
+	}
+}
+
+func TestAccountRoundTrip(t *testing.T) {
+	is := is.New(t)
+	balance := bankaccount.Money{CurrencyCode: bankaccount.USD, Units: 100, Nanos: 0}
+	account, err := bankaccount.NewSavingsAccount(bankaccount.WithBalance(balance))
+	is.NoErr(err)
+	data, err := proto.Marshal(FromAccount("acct-1", account))
+	is.NoErr(err)
+
+	var decoded Account
+	is.NoErr(proto.Unmarshal(data, &decoded))
+	is.Equal(decoded.GetId(), "acct-1")
+	is.Equal(decoded.GetRemittanceAddress(), account.RemittanceAddress())
+	actual, err := decoded.GetBalance().ToMoney()
+	is.NoErr(err)
+	is.Equal(actual, balance)
+}
+
+func TestTransactionTypeRoundTrip(t *testing.T) {
+	is := is.New(t)
+	for _, transactionType := range []bankaccount.TransactionType{
+		bankaccount.TransactionDeposit,
+		bankaccount.TransactionWithdrawal,
+		bankaccount.TransactionFee,
+		bankaccount.TransactionTransferOut,
+		bankaccount.TransactionTransferIn,
+		bankaccount.TransactionOpeningBalance,
+	} {
+		pb := FromTransactionType(transactionType)
+		is.True(pb != TransactionType_TRANSACTION_TYPE_UNSPECIFIED) // every type has an enum value
+		actual, err := pb.ToTransactionType()
+		is.NoErr(err)
+		is.Equal(actual, transactionType)
+	}
+	is.Equal(FromTransactionType(0), TransactionType_TRANSACTION_TYPE_UNSPECIFIED)
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	is := is.New(t)
+	account, err := bankaccount.NewSavingsAccount(bankaccount.WithBalance(bankaccount.Money{CurrencyCode: bankaccount.USD, Units: 100}))
+	is.NoErr(err)
+	is.NoErr(account.Deposit(bankaccount.Money{CurrencyCode: bankaccount.USD, Units: 5, Nanos: 250000000}, bankaccount.WithMemo("birthday")))
+	is.NoErr(account.ChargeFee(bankaccount.Money{CurrencyCode: bankaccount.USD, Units: 1}))
+	transactions := account.Transactions(bankaccount.TransactionFilter{})
+	transactions = append(transactions, bankaccount.Transaction{
+		ID:        "transfer-1",
+		Type:      bankaccount.TransactionTransferOut,
+		Amount:    bankaccount.Money{CurrencyCode: bankaccount.EUR, Units: 1},
+		Balance:   bankaccount.Money{CurrencyCode: bankaccount.EUR, Units: -1, Nanos: -500000000},
+		Timestamp: time.Date(2024, 1, 5, 12, 30, 0, 1, time.UTC),
+	}, bankaccount.Transaction{
+		Type:    bankaccount.TransactionTransferIn,
+		Amount:  bankaccount.Money{CurrencyCode: bankaccount.CAD, Units: 1},
+		Balance: bankaccount.Money{CurrencyCode: bankaccount.CAD, Units: 1},
+	})
+	for _, transaction := range transactions {
+		t.Run(transaction.Type.String(), func(t *testing.T) {
+			is := is.New(t)
+			data, err := proto.Marshal(FromTransaction("acct-1", transaction))
+			is.NoErr(err)
+			var decoded Transaction
+			is.NoErr(proto.Unmarshal(data, &decoded))
+			is.Equal(decoded.GetAccountId(), "acct-1")
+			actual, err := decoded.ToTransaction()
+			is.NoErr(err)
+			is.True(actual.Timestamp.Equal(transaction.Timestamp))
+			actual.Timestamp = transaction.Timestamp
+			is.Equal(actual, transaction)
+		})
+	}
+}
+
+func TestToTransactionErrors(t *testing.T) {
+	usd := &Money{CurrencyCode: "USD", Units: 1}
+	testCases := map[string]*Transaction{
+		"nil":             nil,
+		"unspecified":     {Amount: usd, Balance: usd},
+		"unknown type":    {Type: 99, Amount: usd, Balance: usd},
+		"no amount":       {Type: TransactionType_TRANSACTION_TYPE_DEPOSIT, Balance: usd},
+		"invalid balance": {Type: TransactionType_TRANSACTION_TYPE_DEPOSIT, Amount: usd, Balance: &Money{CurrencyCode: "XYZ"}},
+		"invalid timestamp": {
+			Type: TransactionType_TRANSACTION_TYPE_DEPOSIT, Amount: usd, Balance: usd,
+			Timestamp: &timestamppb.Timestamp{Nanos: -1},
+		},
+	}
+	for name, x := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := x.ToTransaction(); err == nil {
+				t.Errorf("expected an error converting %v", x)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package bankaccountpb contains the protobuf messages and gRPC service definitions for the bankaccount
+// package, along with functions for converting between the generated and the Go types.
+package bankaccountpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative bankaccount.proto
@@ -0,0 +1,120 @@
+package bankaccount
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compare returns -1, 0 or +1 depending on whether m is less than, equal to, or greater than money. An error
+// is returned if either value is invalid or if the currencies differ.
+func (m Money) Compare(money Money) (int, error) {
+	if err := m.checkOperands(money, "comparing"); err != nil {
+		return 0, err
+	}
+	return m.totalNanos().Cmp(money.totalNanos()), nil
+}
+
+func (m Money) LessThan(money Money) (bool, error) {
+	c, err := m.Compare(money)
+	return c < 0, err
+}
+
+func (m Money) LessThanOrEqual(money Money) (bool, error) {
+	c, err := m.Compare(money)
+	return c <= 0 && err == nil, err
+}
+
+func (m Money) GreaterThan(money Money) (bool, error) {
+	c, err := m.Compare(money)
+	return c > 0, err
+}
+
+func (m Money) GreaterThanOrEqual(money Money) (bool, error) {
+	c, err := m.Compare(money)
+	return c >= 0 && err == nil, err
+}
+
+func (m Money) IsZero() bool {
+	return m.Units == 0 && m.Nanos == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Nanos > 0 || m.Units > 0
+}
+
+// Abs returns the absolute value of m. ErrOverflow is returned for the most negative amount, since its
+// absolute value cannot be represented.
+func (m Money) Abs() (Money, error) {
+	if m.IsNegative() {
+		return m.Negate()
+	}
+	return m, m.Validate()
+}
+
+// Negate returns -m. ErrOverflow is returned for the most negative amount, since its negation cannot be
+// represented.
+func (m Money) Negate() (Money, error) {
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	total := m.totalNanos()
+	return fromNanos(m.CurrencyCode, total.Neg(total))
+}
+
+// Min returns the smallest of the given amounts, which must all share the same currency.
+func Min(first Money, rest ...Money) (Money, error) {
+	return extreme(-1, first, rest)
+}
+
+// Max returns the largest of the given amounts, which must all share the same currency.
+func Max(first Money, rest ...Money) (Money, error) {
+	return extreme(1, first, rest)
+}
+
+func extreme(direction int, first Money, rest []Money) (Money, error) {
+	result := first
+	if err := result.Validate(); err != nil {
+		return Money{}, err
+	}
+	for _, m := range rest {
+		c, err := m.Compare(result)
+		if err != nil {
+			return Money{}, err
+		}
+		if c == direction {
+			result = m
+		}
+	}
+	return result, nil
+}
+
+// ByAmount implements sort.Interface for amounts that share the same currency. Use SortMoney to check the
+// amounts before sorting them.
+type ByAmount []Money
+
+func (a ByAmount) Len() int {
+	return len(a)
+}
+
+func (a ByAmount) Less(i, j int) bool {
+	return a[i].totalNanos().Cmp(a[j].totalNanos()) < 0
+}
+
+func (a ByAmount) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+// SortMoney sorts the amounts in ascending order. The amounts are left unchanged and an error is returned if
+// any of them are invalid or if they do not all share the same currency.
+func SortMoney(amounts []Money) error {
+	for i, m := range amounts {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("cannot sort invalid money at index %d: %w", i, err)
+		}
+		if m.CurrencyCode != amounts[0].CurrencyCode {
+			return &CurrencyMismatchError{Operation: "sorting", Expected: amounts[0].CurrencyCode, Actual: m.CurrencyCode}
+		}
+	}
+	sort.Stable(ByAmount(amounts))
+	return nil
+}
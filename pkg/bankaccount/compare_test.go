@@ -0,0 +1,143 @@
+package bankaccount
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		m1       Money
+		m2       Money
+		expected int
+	}{
+		{Money{USD, 0, 0}, Money{USD, 0, 0}, 0},
+		{Money{USD, 1, 1}, Money{USD, 1, 1}, 0},
+		{Money{USD, 1, 1}, Money{USD, 1, 2}, -1},
+		{Money{USD, 1, 2}, Money{USD, 1, 1}, 1},
+		{Money{USD, 0, -1}, Money{USD, 0, 0}, -1},
+		{Money{USD, -1, 0}, Money{USD, 0, -999999999}, -1},
+		{Money{USD, math.MaxInt64, maxNanos}, Money{USD, math.MinInt64, -maxNanos}, 1},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d: %s, %s", i, tc.m1, tc.m2), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			c, err := tc.m1.Compare(tc.m2)
+			is.NoErr(err)
+			is.Equal(c, tc.expected)
+
+			lt, err := tc.m1.LessThan(tc.m2)
+			is.NoErr(err)
+			is.Equal(lt, c < 0) // LessThan
+			lte, err := tc.m1.LessThanOrEqual(tc.m2)
+			is.NoErr(err)
+			is.Equal(lte, c <= 0) // LessThanOrEqual
+			gt, err := tc.m1.GreaterThan(tc.m2)
+			is.NoErr(err)
+			is.Equal(gt, c > 0) // GreaterThan
+			gte, err := tc.m1.GreaterThanOrEqual(tc.m2)
+			is.NoErr(err)
+			is.Equal(gte, c >= 0) // GreaterThanOrEqual
+		})
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := Money{USD, 1, 0}.Compare(Money{CAD, 1, 0})
+	is.True(err != nil) // currency mismatch
+	lte, err := Money{USD, 1, 0}.LessThanOrEqual(Money{CAD, 1, 0})
+	is.True(err != nil) // currency mismatch
+	is.True(!lte)       // not less than or equal when the comparison fails
+	gte, err := Money{USD, 1, 0}.GreaterThanOrEqual(Money{USD, 1, -1})
+	is.True(err != nil) // invalid money
+	is.True(!gte)       // not greater than or equal when the comparison fails
+}
+
+func TestSign(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		zero     bool
+		positive bool
+	}{
+		{Money{USD, 0, 0}, true, false},
+		{Money{USD, 0, 1}, false, true},
+		{Money{USD, 1, 0}, false, true},
+		{Money{USD, 0, -1}, false, false},
+		{Money{USD, -1, 0}, false, false},
+	}
+	for _, tc := range testCases {
+		is := is.New(t)
+		is.Equal(tc.money.IsZero(), tc.zero)         // IsZero
+		is.Equal(tc.money.IsPositive(), tc.positive) // IsPositive
+	}
+}
+
+func TestAbsAndNegate(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		abs      Money
+		negation Money
+	}{
+		{Money{USD, 0, 0}, Money{USD, 0, 0}, Money{USD, 0, 0}},
+		{Money{USD, 1, 5}, Money{USD, 1, 5}, Money{USD, -1, -5}},
+		{Money{USD, -1, -5}, Money{USD, 1, 5}, Money{USD, 1, 5}},
+		{Money{USD, 0, -5}, Money{USD, 0, 5}, Money{USD, 0, 5}},
+		{Money{USD, math.MaxInt64, maxNanos}, Money{USD, math.MaxInt64, maxNanos}, Money{USD, -math.MaxInt64, -maxNanos}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			abs, err := tc.money.Abs()
+			is.NoErr(err)
+			is.Equal(abs, tc.abs) // Abs
+			negation, err := tc.money.Negate()
+			is.NoErr(err)
+			is.Equal(negation, tc.negation) // Negate
+		})
+	}
+	_, err := Money{USD, math.MinInt64, 0}.Abs()
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow but got %v", err)
+	}
+	_, err = Money{USD, 1, -1}.Negate()
+	if err == nil {
+		t.Errorf("expected an error negating invalid money")
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	is := is.New(t)
+	amounts := []Money{{USD, 5, 0}, {USD, -1, 0}, {USD, 7, 1}, {USD, 0, 0}}
+	min, err := Min(amounts[0], amounts[1:]...)
+	is.NoErr(err)
+	is.Equal(min, Money{USD, -1, 0})
+	max, err := Max(amounts[0], amounts[1:]...)
+	is.NoErr(err)
+	is.Equal(max, Money{USD, 7, 1})
+	only, err := Max(Money{USD, 1, 0})
+	is.NoErr(err)
+	is.Equal(only, Money{USD, 1, 0})
+
+	_, err = Min(Money{USD, 1, 0}, Money{CAD, 0, 0})
+	is.True(err != nil) // currency mismatch
+	_, err = Max(Money{USD, 1, -1})
+	is.True(err != nil) // invalid money
+}
+
+func TestSortMoney(t *testing.T) {
+	is := is.New(t)
+	amounts := []Money{{USD, 5, 0}, {USD, -1, 0}, {USD, 0, 1}, {USD, 0, -1}, {USD, 0, 0}}
+	is.NoErr(SortMoney(amounts))
+	is.Equal(amounts, []Money{{USD, -1, 0}, {USD, 0, -1}, {USD, 0, 0}, {USD, 0, 1}, {USD, 5, 0}})
+	is.NoErr(SortMoney(nil))
+
+	mixed := []Money{{USD, 5, 0}, {CAD, 1, 0}}
+	is.True(SortMoney(mixed) != nil)                   // currency mismatch
+	is.Equal(mixed, []Money{{USD, 5, 0}, {CAD, 1, 0}}) // unchanged
+	is.True(SortMoney([]Money{{USD, 1, -1}}) != nil)   // invalid money
+}
@@ -0,0 +1,128 @@
+package bankaccount
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const basisPointsPerUnit = 10000
+
+// WithSpread returns the rate a customer receives when converting at r, the mid-market rate, less a spread
+// given in basis points, e.g., 150 for 1.5%. The result is rounded down to nine decimal places.
+func (r Rate) WithSpread(basisPoints int) (Rate, error) {
+	if basisPoints < 0 || basisPoints >= basisPointsPerUnit {
+		return Rate{}, fmt.Errorf("spread of %d basis points must be between 0 and %d", basisPoints, basisPointsPerUnit-1)
+	}
+	if err := r.Validate(); err != nil {
+		return Rate{}, err
+	}
+	product := new(big.Int).Mul(r.totalNanos(), big.NewInt(int64(basisPointsPerUnit-basisPoints)))
+	spread, err := rateFromNanos(r.From, r.To, RoundFloor.quo(product, big.NewInt(basisPointsPerUnit)))
+	if err != nil {
+		return Rate{}, err
+	}
+	spread.EffectiveAt = r.EffectiveAt
+	spread.Path = r.Path
+	return spread, spread.Validate()
+}
+
+// Fee is what the bank charges for a conversion, in the currency converted to: a percentage of the converted
+// amount given in basis points, plus a fixed amount, but no less than a minimum. Fixed and Minimum may be left
+// as the zero Money when they do not apply.
+type Fee struct {
+	BasisPoints int
+	Fixed       Money
+	Minimum     Money
+}
+
+// FeeSchedule is the Fee for converting to each currency, keyed by currency code. Conversions to currencies
+// that are not in the schedule are free.
+type FeeSchedule map[string]Fee
+
+// charge returns the fee for converting to the given gross amount, rounded half up to the currency's minor
+// unit.
+func (f Fee) charge(gross Money) (Money, error) {
+	if f.BasisPoints < 0 {
+		return Money{}, fmt.Errorf("fee of %d basis points must not be negative", f.BasisPoints)
+	}
+	fee, err := gross.MultiplyWithRounding(f.BasisPoints, -4, RoundHalfUp)
+	if err != nil {
+		return Money{}, err
+	}
+	if f.Fixed != (Money{}) {
+		if fee, err = fee.Add(f.Fixed); err != nil {
+			return Money{}, fmt.Errorf("fixed fee: %w", err)
+		}
+	}
+	if f.Minimum != (Money{}) {
+		if fee, err = Max(fee, f.Minimum); err != nil {
+			return Money{}, fmt.Errorf("minimum fee: %w", err)
+		}
+	}
+	if fee.IsNegative() {
+		return Money{}, fmt.Errorf("fee of %s must not be negative", fee)
+	}
+	return fee.Round(RoundHalfUp)
+}
+
+// Pricing is what the bank charges customers to convert between currencies: a spread off the mid-market rate,
+// given in basis points, and a schedule of fees. The zero Pricing converts at the mid-market rate for free.
+type Pricing struct {
+	SpreadBasisPoints int
+	Fees              FeeSchedule
+}
+
+// ConversionQuote shows a customer exactly what they will receive for converting an amount to another
+// currency.
+type ConversionQuote struct {
+	// Amount is the amount being converted.
+	Amount Money
+	// MidRate is the mid-market rate for the conversion.
+	MidRate Rate
+	// Rate is the rate used for the conversion, i.e., MidRate less the spread.
+	Rate Rate
+	// Gross is Amount converted at Rate, rounded to the minor unit of the currency converted to.
+	Gross Money
+	// Fee is the fee charged for the conversion.
+	Fee Money
+	// Net is Gross less Fee, which is what the customer receives.
+	Net Money
+}
+
+// Quote prices the conversion of an amount at the given mid-market rate. The converted amount is rounded to the
+// minor unit of the currency converted to using the given rounding mode. The amount must not be negative, and
+// it is an error for the fee to exceed the converted amount.
+func (p Pricing) Quote(mid Rate, amount Money, mode RoundingMode) (ConversionQuote, error) {
+	if amount.IsNegative() {
+		return ConversionQuote{}, fmt.Errorf("cannot quote converting a negative amount of %s", amount)
+	}
+	rate, err := mid.WithSpread(p.SpreadBasisPoints)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	gross, err := rate.convertToMinorUnits(amount, mode)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	fee := Money{CurrencyCode: gross.CurrencyCode}
+	if schedule, found := p.Fees[gross.CurrencyCode]; found {
+		if fee, err = schedule.charge(gross); err != nil {
+			return ConversionQuote{}, fmt.Errorf("conversion fee for %s: %w", gross.CurrencyCode, err)
+		}
+	}
+	net, err := gross.Subtract(fee)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	if net.IsNegative() {
+		return ConversionQuote{}, fmt.Errorf("conversion fee of %s exceeds the converted amount of %s", fee, gross)
+	}
+	return ConversionQuote{
+		Amount:  amount,
+		MidRate: mid,
+		Rate:    rate,
+		Gross:   gross,
+		Fee:     fee,
+		Net:     net,
+	}, nil
+}
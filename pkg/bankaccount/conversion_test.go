@@ -0,0 +1,160 @@
+package bankaccount
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithSpread(t *testing.T) {
+	testCases := []struct {
+		mid         Rate
+		basisPoints int
+		expected    Rate
+	}{
+		{Rate{From: CAD, To: USD, Nanos: 800000000}, 0, Rate{From: CAD, To: USD, Nanos: 800000000}},
+		{Rate{From: CAD, To: USD, Nanos: 800000000}, 150, Rate{From: CAD, To: USD, Nanos: 788000000}},
+		{Rate{From: USD, To: CAD, Units: 1, Nanos: 250000000}, 1, Rate{From: USD, To: CAD, Units: 1, Nanos: 249875000}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			actual, err := tc.mid.WithSpread(tc.basisPoints)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+	for _, basisPoints := range []int{-1, 10000} {
+		if _, err := (Rate{From: CAD, To: USD, Nanos: 800000000}).WithSpread(basisPoints); err == nil {
+			t.Errorf("expected an error for a spread of %d basis points", basisPoints)
+		}
+	}
+	if _, err := (Rate{From: CAD, To: USD, Nanos: 1}).WithSpread(1); err == nil {
+		t.Errorf("expected an error for a spread that leaves nothing of the rate")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	mid := Rate{From: CAD, To: USD, Nanos: 800000000}
+	testCases := []struct {
+		mid      Rate
+		pricing  Pricing
+		amount   Money
+		gross    Money
+		fee      Money
+		expected Money
+	}{
+		{mid, Pricing{}, Money{CAD, 100, 0}, Money{USD, 80, 0}, Money{USD, 0, 0}, Money{USD, 80, 0}},
+		{mid, Pricing{SpreadBasisPoints: 150}, Money{CAD, 100, 0}, Money{USD, 78, 800000000}, Money{USD, 0, 0}, Money{USD, 78, 800000000}},
+		{
+			mid, Pricing{SpreadBasisPoints: 150, Fees: FeeSchedule{USD: {BasisPoints: 50, Fixed: Money{USD, 1, 0}}}},
+			Money{CAD, 100, 0}, Money{USD, 78, 800000000}, Money{USD, 1, 390000000}, Money{USD, 77, 410000000},
+		},
+		{
+			mid, Pricing{Fees: FeeSchedule{USD: {BasisPoints: 50, Minimum: Money{USD, 5, 0}}}},
+			Money{CAD, 100, 0}, Money{USD, 80, 0}, Money{USD, 5, 0}, Money{USD, 75, 0},
+		},
+		{
+			mid, Pricing{Fees: FeeSchedule{CAD: {Fixed: Money{CAD, 5, 0}}}},
+			Money{CAD, 100, 0}, Money{USD, 80, 0}, Money{USD, 0, 0}, Money{USD, 80, 0},
+		},
+		{mid, Pricing{}, Money{CAD, 0, 1}, Money{USD, 0, 0}, Money{USD, 0, 0}, Money{USD, 0, 0}},
+		// USD 0.01499999997 is rounded once to the cent, not to USD 0.015000000 and then up to USD 0.02
+		{
+			Rate{From: CAD, To: USD, Nanos: 499999999}, Pricing{},
+			Money{CAD, 0, 30000000}, Money{USD, 0, 10000000}, Money{USD, 0, 0}, Money{USD, 0, 10000000},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			quote, err := tc.pricing.Quote(tc.mid, tc.amount, RoundHalfUp)
+			is.NoErr(err)
+			is.Equal(quote.Amount, tc.amount) // Amount
+			is.Equal(quote.MidRate, tc.mid)   // MidRate
+			is.Equal(quote.Gross, tc.gross)   // Gross
+			is.Equal(quote.Fee, tc.fee)       // Fee
+			is.Equal(quote.Net, tc.expected)  // Net
+		})
+	}
+}
+
+func TestQuoteRoundsToMinorUnits(t *testing.T) {
+	is := is.New(t)
+	pricing := Pricing{Fees: FeeSchedule{"JPY": {BasisPoints: 25}}}
+	quote, err := pricing.Quote(Rate{From: USD, To: "JPY", Units: 144, Nanos: 555000000}, Money{USD, 10, 0}, RoundHalfEven)
+	is.NoErr(err)
+	is.Equal(quote.Gross, Money{"JPY", 1446, 0}) // 1445.55 rounded to whole yen
+	is.Equal(quote.Fee, Money{"JPY", 4, 0})      // 3.615 rounded half up
+	is.Equal(quote.Net, Money{"JPY", 1442, 0})
+}
+
+func TestQuoteErrors(t *testing.T) {
+	mid := Rate{From: CAD, To: USD, Nanos: 800000000}
+	testCases := []struct {
+		pricing Pricing
+		amount  Money
+	}{
+		{Pricing{SpreadBasisPoints: -1}, Money{CAD, 100, 0}},
+		{Pricing{}, Money{EUR, 100, 0}},
+		{Pricing{}, Money{CAD, -100, 0}},
+		{Pricing{Fees: FeeSchedule{USD: {Fixed: Money{USD, 81, 0}}}}, Money{CAD, 100, 0}},
+		{Pricing{Fees: FeeSchedule{USD: {Fixed: Money{CAD, 1, 0}}}}, Money{CAD, 100, 0}},
+		{Pricing{Fees: FeeSchedule{USD: {Minimum: Money{CAD, 1, 0}}}}, Money{CAD, 100, 0}},
+		{Pricing{Fees: FeeSchedule{USD: {BasisPoints: -10}}}, Money{CAD, 100, 0}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			if _, err := tc.pricing.Quote(mid, tc.amount, RoundHalfUp); err == nil {
+				t.Errorf("expected an error quoting %s with %+v", tc.amount, tc.pricing)
+			}
+		})
+	}
+}
+
+func TestQuoteBalanceAsCurrency(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t,
+		WithBalance(Money{CAD, 100, 0}),
+		WithPricing(Pricing{SpreadBasisPoints: 150, Fees: FeeSchedule{USD: {Fixed: Money{USD, 2, 500000000}}}}),
+	)
+	quote, err := acct.QuoteBalanceAsCurrency(USD)
+	is.NoErr(err)
+	is.Equal(quote.Rate.String(), "CAD/USD 0.788")
+	is.Equal(quote.Net, Money{USD, 76, 300000000})
+	mid, err := acct.BalanceAsCurrency(USD)
+	is.NoErr(err)
+	is.Equal(mid, Money{USD, 80, 0}) // the balance is still valued at the mid-market rate
+	_, err = acct.QuoteBalanceAsCurrency("JPY")
+	is.True(err != nil) // no rate
+}
+
+func TestAutoConversion(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t,
+		WithBalance(Money{USD, 10, 0}),
+		WithRoundingMode(RoundHalfEven),
+		WithAutoConversion(NewExchangeRates(Rate{From: CAD, To: USD, Nanos: 733333333})),
+	)
+	is.NoErr(acct.Deposit(Money{CAD, 10, 0}))
+	is.NoErr(acct.Deposit(Money{USD, 1, 0}))
+	is.NoErr(acct.Withdraw(Money{CAD, 1, 500000000}))
+	is.Equal(acct.Balance(), Money{USD, 17, 230000000}) // 10 + 7.33 + 1 - 1.10
+	is.Equal(acct.Conversions(), []Conversion{
+		{Original: Money{CAD, 10, 0}, Rate: Rate{From: CAD, To: USD, Nanos: 733333333}, Converted: Money{USD, 7, 330000000}},
+		{Original: Money{CAD, 1, 500000000}, Rate: Rate{From: CAD, To: USD, Nanos: 733333333}, Converted: Money{USD, 1, 100000000}},
+	}) // deposits in the account's currency are not converted
+
+	is.True(errors.Is(acct.Deposit(Money{EUR, 1, 0}), ErrRateNotFound))         // no rate
+	is.True(errors.Is(acct.Withdraw(Money{CAD, 100, 0}), ErrInsufficientFunds)) // would overdraw
+	is.Equal(len(acct.Conversions()), 2)                                        // failed transactions are not recorded
+	is.True(errors.Is(mustNewSavingsAccount(t).Deposit(Money{CAD, 1, 0}), ErrCurrencyMismatch))
+
+	// USD 0.01499999997 is rounded once to the cent, not to USD 0.015000000 and then up to USD 0.02
+	acct = mustNewSavingsAccount(t, WithAutoConversion(NewExchangeRates(Rate{From: CAD, To: USD, Nanos: 499999999})))
+	is.NoErr(acct.Deposit(Money{CAD, 0, 30000000}))
+	is.Equal(acct.Balance(), Money{USD, 0, 10000000})
+	is.Equal(acct.Conversions()[0].Converted, Money{USD, 0, 10000000})
+}
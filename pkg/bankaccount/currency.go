@@ -0,0 +1,232 @@
+package bankaccount
+
+import "fmt"
+
+const (
+	USD = "USD"
+	CAD = "CAD"
+	CNY = "CNY"
+	EUR = "EUR"
+)
+
+// Currency describes a currency as defined in ISO 4217.
+type Currency struct {
+	// The three-letter alphabetic code, e.g., "USD".
+	Code string
+	// The three-digit numeric code, e.g., "840".
+	Numeric string
+	// The name of the currency, e.g., "US Dollar".
+	Name string
+	// The symbol commonly used to display amounts, e.g., "$". If a currency has no unambiguous symbol, the
+	// alphabetic code is used.
+	Symbol string
+	// The number of digits after the decimal separator in the currency's minor unit, e.g., 2 for USD (cents),
+	// 0 for JPY and 3 for KWD.
+	MinorUnits int
+}
+
+// The active ISO 4217 currencies, sorted by code. Precious metals, testing codes, and other codes without a defined minor unit
+// are not included.
+var iso4217 = []Currency{
+	{Code: "AED", Numeric: "784", Name: "UAE Dirham", Symbol: "AED", MinorUnits: 2},
+	{Code: "AFN", Numeric: "971", Name: "Afghani", Symbol: "؋", MinorUnits: 2},
+	{Code: "ALL", Numeric: "008", Name: "Lek", Symbol: "ALL", MinorUnits: 2},
+	{Code: "AMD", Numeric: "051", Name: "Armenian Dram", Symbol: "֏", MinorUnits: 2},
+	{Code: "AOA", Numeric: "973", Name: "Kwanza", Symbol: "Kz", MinorUnits: 2},
+	{Code: "ARS", Numeric: "032", Name: "Argentine Peso", Symbol: "ARS", MinorUnits: 2},
+	{Code: "AUD", Numeric: "036", Name: "Australian Dollar", Symbol: "A$", MinorUnits: 2},
+	{Code: "AWG", Numeric: "533", Name: "Aruban Florin", Symbol: "AWG", MinorUnits: 2},
+	{Code: "AZN", Numeric: "944", Name: "Azerbaijan Manat", Symbol: "₼", MinorUnits: 2},
+	{Code: "BAM", Numeric: "977", Name: "Convertible Mark", Symbol: "KM", MinorUnits: 2},
+	{Code: "BBD", Numeric: "052", Name: "Barbados Dollar", Symbol: "BBD", MinorUnits: 2},
+	{Code: "BDT", Numeric: "050", Name: "Taka", Symbol: "৳", MinorUnits: 2},
+	{Code: "BHD", Numeric: "048", Name: "Bahraini Dinar", Symbol: "BHD", MinorUnits: 3},
+	{Code: "BIF", Numeric: "108", Name: "Burundi Franc", Symbol: "BIF", MinorUnits: 0},
+	{Code: "BMD", Numeric: "060", Name: "Bermudian Dollar", Symbol: "BMD", MinorUnits: 2},
+	{Code: "BND", Numeric: "096", Name: "Brunei Dollar", Symbol: "BND", MinorUnits: 2},
+	{Code: "BOB", Numeric: "068", Name: "Boliviano", Symbol: "Bs", MinorUnits: 2},
+	{Code: "BOV", Numeric: "984", Name: "Mvdol", Symbol: "BOV", MinorUnits: 2},
+	{Code: "BRL", Numeric: "986", Name: "Brazilian Real", Symbol: "R$", MinorUnits: 2},
+	{Code: "BSD", Numeric: "044", Name: "Bahamian Dollar", Symbol: "BSD", MinorUnits: 2},
+	{Code: "BTN", Numeric: "064", Name: "Ngultrum", Symbol: "BTN", MinorUnits: 2},
+	{Code: "BWP", Numeric: "072", Name: "Pula", Symbol: "BWP", MinorUnits: 2},
+	{Code: "BYN", Numeric: "933", Name: "Belarusian Ruble", Symbol: "BYN", MinorUnits: 2},
+	{Code: "BZD", Numeric: "084", Name: "Belize Dollar", Symbol: "BZD", MinorUnits: 2},
+	{Code: "CAD", Numeric: "124", Name: "Canadian Dollar", Symbol: "CA$", MinorUnits: 2},
+	{Code: "CDF", Numeric: "976", Name: "Congolese Franc", Symbol: "CDF", MinorUnits: 2},
+	{Code: "CHE", Numeric: "947", Name: "WIR Euro", Symbol: "CHE", MinorUnits: 2},
+	{Code: "CHF", Numeric: "756", Name: "Swiss Franc", Symbol: "CHF", MinorUnits: 2},
+	{Code: "CHW", Numeric: "948", Name: "WIR Franc", Symbol: "CHW", MinorUnits: 2},
+	{Code: "CLF", Numeric: "990", Name: "Unidad de Fomento", Symbol: "CLF", MinorUnits: 4},
+	{Code: "CLP", Numeric: "152", Name: "Chilean Peso", Symbol: "CLP", MinorUnits: 0},
+	{Code: "CNY", Numeric: "156", Name: "Yuan Renminbi", Symbol: "CN¥", MinorUnits: 2},
+	{Code: "COP", Numeric: "170", Name: "Colombian Peso", Symbol: "COP", MinorUnits: 2},
+	{Code: "COU", Numeric: "970", Name: "Unidad de Valor Real", Symbol: "COU", MinorUnits: 2},
+	{Code: "CRC", Numeric: "188", Name: "Costa Rican Colon", Symbol: "₡", MinorUnits: 2},
+	{Code: "CUP", Numeric: "192", Name: "Cuban Peso", Symbol: "CUP", MinorUnits: 2},
+	{Code: "CVE", Numeric: "132", Name: "Cabo Verde Escudo", Symbol: "CVE", MinorUnits: 2},
+	{Code: "CZK", Numeric: "203", Name: "Czech Koruna", Symbol: "CZK", MinorUnits: 2},
+	{Code: "DJF", Numeric: "262", Name: "Djibouti Franc", Symbol: "DJF", MinorUnits: 0},
+	{Code: "DKK", Numeric: "208", Name: "Danish Krone", Symbol: "DKK", MinorUnits: 2},
+	{Code: "DOP", Numeric: "214", Name: "Dominican Peso", Symbol: "DOP", MinorUnits: 2},
+	{Code: "DZD", Numeric: "012", Name: "Algerian Dinar", Symbol: "DZD", MinorUnits: 2},
+	{Code: "EGP", Numeric: "818", Name: "Egyptian Pound", Symbol: "EGP", MinorUnits: 2},
+	{Code: "ERN", Numeric: "232", Name: "Nakfa", Symbol: "ERN", MinorUnits: 2},
+	{Code: "ETB", Numeric: "230", Name: "Ethiopian Birr", Symbol: "ETB", MinorUnits: 2},
+	{Code: "EUR", Numeric: "978", Name: "Euro", Symbol: "€", MinorUnits: 2},
+	{Code: "FJD", Numeric: "242", Name: "Fiji Dollar", Symbol: "FJD", MinorUnits: 2},
+	{Code: "FKP", Numeric: "238", Name: "Falkland Islands Pound", Symbol: "FKP", MinorUnits: 2},
+	{Code: "GBP", Numeric: "826", Name: "Pound Sterling", Symbol: "£", MinorUnits: 2},
+	{Code: "GEL", Numeric: "981", Name: "Lari", Symbol: "₾", MinorUnits: 2},
+	{Code: "GHS", Numeric: "936", Name: "Ghana Cedi", Symbol: "GH₵", MinorUnits: 2},
+	{Code: "GIP", Numeric: "292", Name: "Gibraltar Pound", Symbol: "GIP", MinorUnits: 2},
+	{Code: "GMD", Numeric: "270", Name: "Dalasi", Symbol: "GMD", MinorUnits: 2},
+	{Code: "GNF", Numeric: "324", Name: "Guinean Franc", Symbol: "GNF", MinorUnits: 0},
+	{Code: "GTQ", Numeric: "320", Name: "Quetzal", Symbol: "GTQ", MinorUnits: 2},
+	{Code: "GYD", Numeric: "328", Name: "Guyana Dollar", Symbol: "GYD", MinorUnits: 2},
+	{Code: "HKD", Numeric: "344", Name: "Hong Kong Dollar", Symbol: "HK$", MinorUnits: 2},
+	{Code: "HNL", Numeric: "340", Name: "Lempira", Symbol: "HNL", MinorUnits: 2},
+	{Code: "HTG", Numeric: "332", Name: "Gourde", Symbol: "HTG", MinorUnits: 2},
+	{Code: "HUF", Numeric: "348", Name: "Forint", Symbol: "HUF", MinorUnits: 2},
+	{Code: "IDR", Numeric: "360", Name: "Rupiah", Symbol: "IDR", MinorUnits: 2},
+	{Code: "ILS", Numeric: "376", Name: "New Israeli Sheqel", Symbol: "₪", MinorUnits: 2},
+	{Code: "INR", Numeric: "356", Name: "Indian Rupee", Symbol: "₹", MinorUnits: 2},
+	{Code: "IQD", Numeric: "368", Name: "Iraqi Dinar", Symbol: "IQD", MinorUnits: 3},
+	{Code: "IRR", Numeric: "364", Name: "Iranian Rial", Symbol: "IRR", MinorUnits: 2},
+	{Code: "ISK", Numeric: "352", Name: "Iceland Krona", Symbol: "ISK", MinorUnits: 0},
+	{Code: "JMD", Numeric: "388", Name: "Jamaican Dollar", Symbol: "JMD", MinorUnits: 2},
+	{Code: "JOD", Numeric: "400", Name: "Jordanian Dinar", Symbol: "JOD", MinorUnits: 3},
+	{Code: "JPY", Numeric: "392", Name: "Yen", Symbol: "¥", MinorUnits: 0},
+	{Code: "KES", Numeric: "404", Name: "Kenyan Shilling", Symbol: "KES", MinorUnits: 2},
+	{Code: "KGS", Numeric: "417", Name: "Som", Symbol: "KGS", MinorUnits: 2},
+	{Code: "KHR", Numeric: "116", Name: "Riel", Symbol: "KHR", MinorUnits: 2},
+	{Code: "KMF", Numeric: "174", Name: "Comorian Franc", Symbol: "KMF", MinorUnits: 0},
+	{Code: "KPW", Numeric: "408", Name: "North Korean Won", Symbol: "KPW", MinorUnits: 2},
+	{Code: "KRW", Numeric: "410", Name: "Won", Symbol: "₩", MinorUnits: 0},
+	{Code: "KWD", Numeric: "414", Name: "Kuwaiti Dinar", Symbol: "KWD", MinorUnits: 3},
+	{Code: "KYD", Numeric: "136", Name: "Cayman Islands Dollar", Symbol: "KYD", MinorUnits: 2},
+	{Code: "KZT", Numeric: "398", Name: "Tenge", Symbol: "₸", MinorUnits: 2},
+	{Code: "LAK", Numeric: "418", Name: "Lao Kip", Symbol: "₭", MinorUnits: 2},
+	{Code: "LBP", Numeric: "422", Name: "Lebanese Pound", Symbol: "LBP", MinorUnits: 2},
+	{Code: "LKR", Numeric: "144", Name: "Sri Lanka Rupee", Symbol: "LKR", MinorUnits: 2},
+	{Code: "LRD", Numeric: "430", Name: "Liberian Dollar", Symbol: "LRD", MinorUnits: 2},
+	{Code: "LSL", Numeric: "426", Name: "Loti", Symbol: "LSL", MinorUnits: 2},
+	{Code: "LYD", Numeric: "434", Name: "Libyan Dinar", Symbol: "LYD", MinorUnits: 3},
+	{Code: "MAD", Numeric: "504", Name: "Moroccan Dirham", Symbol: "MAD", MinorUnits: 2},
+	{Code: "MDL", Numeric: "498", Name: "Moldovan Leu", Symbol: "MDL", MinorUnits: 2},
+	{Code: "MGA", Numeric: "969", Name: "Malagasy Ariary", Symbol: "MGA", MinorUnits: 2},
+	{Code: "MKD", Numeric: "807", Name: "Denar", Symbol: "MKD", MinorUnits: 2},
+	{Code: "MMK", Numeric: "104", Name: "Kyat", Symbol: "MMK", MinorUnits: 2},
+	{Code: "MNT", Numeric: "496", Name: "Tugrik", Symbol: "₮", MinorUnits: 2},
+	{Code: "MOP", Numeric: "446", Name: "Pataca", Symbol: "MOP", MinorUnits: 2},
+	{Code: "MRU", Numeric: "929", Name: "Ouguiya", Symbol: "MRU", MinorUnits: 2},
+	{Code: "MUR", Numeric: "480", Name: "Mauritius Rupee", Symbol: "MUR", MinorUnits: 2},
+	{Code: "MVR", Numeric: "462", Name: "Rufiyaa", Symbol: "MVR", MinorUnits: 2},
+	{Code: "MWK", Numeric: "454", Name: "Malawi Kwacha", Symbol: "MWK", MinorUnits: 2},
+	{Code: "MXN", Numeric: "484", Name: "Mexican Peso", Symbol: "MX$", MinorUnits: 2},
+	{Code: "MXV", Numeric: "979", Name: "Mexican Unidad de Inversion (UDI)", Symbol: "MXV", MinorUnits: 2},
+	{Code: "MYR", Numeric: "458", Name: "Malaysian Ringgit", Symbol: "RM", MinorUnits: 2},
+	{Code: "MZN", Numeric: "943", Name: "Mozambique Metical", Symbol: "MZN", MinorUnits: 2},
+	{Code: "NAD", Numeric: "516", Name: "Namibia Dollar", Symbol: "NAD", MinorUnits: 2},
+	{Code: "NGN", Numeric: "566", Name: "Naira", Symbol: "₦", MinorUnits: 2},
+	{Code: "NIO", Numeric: "558", Name: "Cordoba Oro", Symbol: "NIO", MinorUnits: 2},
+	{Code: "NOK", Numeric: "578", Name: "Norwegian Krone", Symbol: "NOK", MinorUnits: 2},
+	{Code: "NPR", Numeric: "524", Name: "Nepalese Rupee", Symbol: "NPR", MinorUnits: 2},
+	{Code: "NZD", Numeric: "554", Name: "New Zealand Dollar", Symbol: "NZ$", MinorUnits: 2},
+	{Code: "OMR", Numeric: "512", Name: "Rial Omani", Symbol: "OMR", MinorUnits: 3},
+	{Code: "PAB", Numeric: "590", Name: "Balboa", Symbol: "PAB", MinorUnits: 2},
+	{Code: "PEN", Numeric: "604", Name: "Sol", Symbol: "PEN", MinorUnits: 2},
+	{Code: "PGK", Numeric: "598", Name: "Kina", Symbol: "PGK", MinorUnits: 2},
+	{Code: "PHP", Numeric: "608", Name: "Philippine Peso", Symbol: "₱", MinorUnits: 2},
+	{Code: "PKR", Numeric: "586", Name: "Pakistan Rupee", Symbol: "PKR", MinorUnits: 2},
+	{Code: "PLN", Numeric: "985", Name: "Zloty", Symbol: "zł", MinorUnits: 2},
+	{Code: "PYG", Numeric: "600", Name: "Guarani", Symbol: "₲", MinorUnits: 0},
+	{Code: "QAR", Numeric: "634", Name: "Qatari Rial", Symbol: "QAR", MinorUnits: 2},
+	{Code: "RON", Numeric: "946", Name: "Romanian Leu", Symbol: "RON", MinorUnits: 2},
+	{Code: "RSD", Numeric: "941", Name: "Serbian Dinar", Symbol: "RSD", MinorUnits: 2},
+	{Code: "RUB", Numeric: "643", Name: "Russian Ruble", Symbol: "₽", MinorUnits: 2},
+	{Code: "RWF", Numeric: "646", Name: "Rwanda Franc", Symbol: "RWF", MinorUnits: 0},
+	{Code: "SAR", Numeric: "682", Name: "Saudi Riyal", Symbol: "SAR", MinorUnits: 2},
+	{Code: "SBD", Numeric: "090", Name: "Solomon Islands Dollar", Symbol: "SBD", MinorUnits: 2},
+	{Code: "SCR", Numeric: "690", Name: "Seychelles Rupee", Symbol: "SCR", MinorUnits: 2},
+	{Code: "SDG", Numeric: "938", Name: "Sudanese Pound", Symbol: "SDG", MinorUnits: 2},
+	{Code: "SEK", Numeric: "752", Name: "Swedish Krona", Symbol: "SEK", MinorUnits: 2},
+	{Code: "SGD", Numeric: "702", Name: "Singapore Dollar", Symbol: "S$", MinorUnits: 2},
+	{Code: "SHP", Numeric: "654", Name: "Saint Helena Pound", Symbol: "SHP", MinorUnits: 2},
+	{Code: "SLE", Numeric: "925", Name: "Leone", Symbol: "SLE", MinorUnits: 2},
+	{Code: "SOS", Numeric: "706", Name: "Somali Shilling", Symbol: "SOS", MinorUnits: 2},
+	{Code: "SRD", Numeric: "968", Name: "Surinam Dollar", Symbol: "SRD", MinorUnits: 2},
+	{Code: "SSP", Numeric: "728", Name: "South Sudanese Pound", Symbol: "SSP", MinorUnits: 2},
+	{Code: "STN", Numeric: "930", Name: "Dobra", Symbol: "STN", MinorUnits: 2},
+	{Code: "SVC", Numeric: "222", Name: "El Salvador Colon", Symbol: "SVC", MinorUnits: 2},
+	{Code: "SYP", Numeric: "760", Name: "Syrian Pound", Symbol: "SYP", MinorUnits: 2},
+	{Code: "SZL", Numeric: "748", Name: "Lilangeni", Symbol: "SZL", MinorUnits: 2},
+	{Code: "THB", Numeric: "764", Name: "Baht", Symbol: "฿", MinorUnits: 2},
+	{Code: "TJS", Numeric: "972", Name: "Somoni", Symbol: "TJS", MinorUnits: 2},
+	{Code: "TMT", Numeric: "934", Name: "Turkmenistan New Manat", Symbol: "TMT", MinorUnits: 2},
+	{Code: "TND", Numeric: "788", Name: "Tunisian Dinar", Symbol: "TND", MinorUnits: 3},
+	{Code: "TOP", Numeric: "776", Name: "Pa'anga", Symbol: "T$", MinorUnits: 2},
+	{Code: "TRY", Numeric: "949", Name: "Turkish Lira", Symbol: "₺", MinorUnits: 2},
+	{Code: "TTD", Numeric: "780", Name: "Trinidad and Tobago Dollar", Symbol: "TTD", MinorUnits: 2},
+	{Code: "TWD", Numeric: "901", Name: "New Taiwan Dollar", Symbol: "NT$", MinorUnits: 2},
+	{Code: "TZS", Numeric: "834", Name: "Tanzanian Shilling", Symbol: "TZS", MinorUnits: 2},
+	{Code: "UAH", Numeric: "980", Name: "Hryvnia", Symbol: "₴", MinorUnits: 2},
+	{Code: "UGX", Numeric: "800", Name: "Uganda Shilling", Symbol: "UGX", MinorUnits: 0},
+	{Code: "USD", Numeric: "840", Name: "US Dollar", Symbol: "$", MinorUnits: 2},
+	{Code: "USN", Numeric: "997", Name: "US Dollar (Next day)", Symbol: "USN", MinorUnits: 2},
+	{Code: "UYI", Numeric: "940", Name: "Uruguay Peso en Unidades Indexadas (UI)", Symbol: "UYI", MinorUnits: 0},
+	{Code: "UYU", Numeric: "858", Name: "Peso Uruguayo", Symbol: "UYU", MinorUnits: 2},
+	{Code: "UYW", Numeric: "927", Name: "Unidad Previsional", Symbol: "UYW", MinorUnits: 4},
+	{Code: "UZS", Numeric: "860", Name: "Uzbekistan Sum", Symbol: "UZS", MinorUnits: 2},
+	{Code: "VED", Numeric: "926", Name: "Bolívar Soberano", Symbol: "VED", MinorUnits: 2},
+	{Code: "VES", Numeric: "928", Name: "Bolívar Soberano", Symbol: "VES", MinorUnits: 2},
+	{Code: "VND", Numeric: "704", Name: "Dong", Symbol: "₫", MinorUnits: 0},
+	{Code: "VUV", Numeric: "548", Name: "Vatu", Symbol: "VUV", MinorUnits: 0},
+	{Code: "WST", Numeric: "882", Name: "Tala", Symbol: "WST", MinorUnits: 2},
+	{Code: "XAF", Numeric: "950", Name: "CFA Franc BEAC", Symbol: "FCFA", MinorUnits: 0},
+	{Code: "XCD", Numeric: "951", Name: "East Caribbean Dollar", Symbol: "EC$", MinorUnits: 2},
+	{Code: "XCG", Numeric: "532", Name: "Caribbean Guilder", Symbol: "XCG", MinorUnits: 2},
+	{Code: "XOF", Numeric: "952", Name: "CFA Franc BCEAO", Symbol: "F CFA", MinorUnits: 0},
+	{Code: "XPF", Numeric: "953", Name: "CFP Franc", Symbol: "CFPF", MinorUnits: 0},
+	{Code: "YER", Numeric: "886", Name: "Yemeni Rial", Symbol: "YER", MinorUnits: 2},
+	{Code: "ZAR", Numeric: "710", Name: "Rand", Symbol: "R", MinorUnits: 2},
+	{Code: "ZMW", Numeric: "967", Name: "Zambian Kwacha", Symbol: "ZMW", MinorUnits: 2},
+	{Code: "ZWG", Numeric: "924", Name: "Zimbabwe Gold", Symbol: "ZWG", MinorUnits: 2},
+}
+
+var currenciesByCode = func() map[string]Currency {
+	byCode := make(map[string]Currency, len(iso4217))
+	for _, c := range iso4217 {
+		byCode[c.Code] = c
+	}
+	return byCode
+}()
+
+// LookupCurrency returns the ISO 4217 currency for the given alphabetic code. Codes are case sensitive, so "usd"
+// is not a known currency.
+func LookupCurrency(code string) (Currency, error) {
+	c, found := currenciesByCode[code]
+	if !found {
+		return Currency{}, fmt.Errorf("unknown currency code %q", code)
+	}
+	return c, nil
+}
+
+// Currencies returns all known currencies, sorted by code.
+func Currencies() []Currency {
+	currencies := make([]Currency, len(iso4217))
+	copy(currencies, iso4217)
+	return currencies
+}
+
+// Round rounds m to the precision of its currency's minor unit, e.g., to whole cents for USD or to whole yen
+// for JPY, using the given rounding mode.
+func (m Money) Round(mode RoundingMode) (Money, error) {
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	c, _ := LookupCurrency(m.CurrencyCode)
+	minorUnit := pow10(9 - c.MinorUnits)
+	rounded := mode.quo(m.totalNanos(), minorUnit)
+	return fromNanos(m.CurrencyCode, rounded.Mul(rounded, minorUnit))
+}
@@ -0,0 +1,101 @@
+package bankaccount
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestLookupCurrency(t *testing.T) {
+	testCases := []struct {
+		code       string
+		numeric    string
+		symbol     string
+		minorUnits int
+	}{
+		{"USD", "840", "$", 2},
+		{"EUR", "978", "€", 2},
+		{"JPY", "392", "¥", 0},
+		{"KWD", "414", "KWD", 3},
+		{"CLF", "990", "CLF", 4},
+		{"ALL", "008", "ALL", 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			c, err := LookupCurrency(tc.code)
+			is.NoErr(err)
+			is.Equal(c.Code, tc.code)             // Code
+			is.Equal(c.Numeric, tc.numeric)       // Numeric
+			is.Equal(c.Symbol, tc.symbol)         // Symbol
+			is.Equal(c.MinorUnits, tc.minorUnits) // MinorUnits
+		})
+	}
+}
+
+func TestLookupUnknownCurrency(t *testing.T) {
+	for _, code := range []string{"", "usd", "US", "USDD", "XYZ"} {
+		t.Run(code, func(t *testing.T) {
+			_, err := LookupCurrency(code)
+			if err == nil {
+				t.Errorf("expected an error for currency code %q", code)
+			}
+		})
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	is := is.New(t)
+	currencies := Currencies()
+	numerics := map[string]bool{}
+	for i, c := range currencies {
+		if i > 0 {
+			is.True(currencies[i-1].Code < c.Code) // sorted by code
+		}
+		is.True(!numerics[c.Numeric]) // numeric codes are unique
+		numerics[c.Numeric] = true
+	}
+	currencies[0].Code = "ZZZ"
+	is.True(Currencies()[0].Code != "ZZZ") // the registry cannot be modified by callers
+}
+
+func TestNewMoneyRejectsUnknownCurrency(t *testing.T) {
+	is := is.New(t)
+	_, err := NewMoney("USX", 1, 0)
+	is.True(err != nil)
+	m, err := NewMoney("JPY", 1, 0)
+	is.NoErr(err)
+	is.Equal(m, Money{"JPY", 1, 0})
+}
+
+func TestRound(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		mode     RoundingMode
+		expected Money
+	}{
+		{Money{USD, 1, 5000000}, RoundHalfUp, Money{USD, 1, 10000000}},
+		{Money{USD, 1, 5000000}, RoundHalfEven, Money{USD, 1, 0}},
+		{Money{USD, 1, 15000000}, RoundHalfEven, Money{USD, 1, 20000000}},
+		{Money{USD, -1, -5000000}, RoundHalfUp, Money{USD, -1, -10000000}},
+		{Money{USD, 1, 999999999}, RoundFloor, Money{USD, 1, 990000000}},
+		{Money{USD, 1, 995000000}, RoundHalfUp, Money{USD, 2, 0}},
+		{Money{"JPY", 100, 500000000}, RoundHalfEven, Money{"JPY", 100, 0}},
+		{Money{"JPY", 101, 500000000}, RoundHalfEven, Money{"JPY", 102, 0}},
+		{Money{"KWD", 1, 234500000}, RoundHalfUp, Money{"KWD", 1, 235000000}},
+		{Money{"KWD", 1, 234500000}, RoundTowardZero, Money{"KWD", 1, 234000000}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			actual, err := tc.money.Round(tc.mode)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+	_, err := Money{"XYZ", 1, 0}.Round(RoundHalfUp)
+	if err == nil {
+		t.Errorf("expected an error rounding an unknown currency")
+	}
+}
@@ -0,0 +1,100 @@
+package bankaccount
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInsufficientFunds is matched by an InsufficientFundsError, which is returned when a debit would
+	// overdraw a balance.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrCurrencyMismatch is matched by a CurrencyMismatchError, which is returned when amounts in different
+	// currencies are combined without converting them first.
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrRateNotFound is matched by a RateNotFoundError, which is returned when there is no exchange rate
+	// between two currencies.
+	ErrRateNotFound = errors.New("exchange rate not found")
+	// ErrInvalidMoney is matched by an InvalidMoneyError, which is returned when a Money is invalid or text
+	// cannot be parsed as one.
+	ErrInvalidMoney = errors.New("invalid money")
+)
+
+// InsufficientFundsError is returned when a debit would overdraw a balance.
+type InsufficientFundsError struct {
+	// Operation is what would have overdrawn the balance, e.g., "withdrawal".
+	Operation string
+	Requested Money
+	Available Money
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("%s of %s would overdraw from balance of %s", e.Operation, e.Requested, e.Available)
+}
+
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// CurrencyMismatchError is returned when an amount is not in the currency an operation requires.
+type CurrencyMismatchError struct {
+	// Operation is what was being attempted, e.g., "adding".
+	Operation string
+	// Expected is the currency the operation requires, and Actual is the currency of the amount given.
+	Expected string
+	Actual   string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("you must convert %s to %s using current exchange rates before %s", e.Actual, e.Expected, e.Operation)
+}
+
+func (e *CurrencyMismatchError) Is(target error) bool {
+	return target == ErrCurrencyMismatch
+}
+
+// RateNotFoundError is returned when there is no exchange rate for converting from one currency to another.
+type RateNotFoundError struct {
+	From string
+	To   string
+}
+
+func (e *RateNotFoundError) Error() string {
+	return fmt.Sprintf("currency code not found in current exchange tables: no rate from %s to %s", e.From, e.To)
+}
+
+func (e *RateNotFoundError) Is(target error) bool {
+	return target == ErrRateNotFound
+}
+
+// InvalidMoneyError is returned when a Money is invalid, or when text cannot be parsed as one. It wraps the
+// reason, so, e.g., errors.Is(err, ErrOverflow) reports whether the amount was out of range.
+type InvalidMoneyError struct {
+	// Input is the text that could not be parsed, if the money was parsed.
+	Input string
+	// Money is the invalid value, if the money was not parsed.
+	Money Money
+	// Err is the reason the money is invalid.
+	Err    error
+	parsed bool
+}
+
+func (e *InvalidMoneyError) Error() string {
+	if e.parsed {
+		return fmt.Sprintf("invalid money %q: %v", e.Input, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *InvalidMoneyError) Is(target error) bool {
+	return target == ErrInvalidMoney
+}
+
+func (e *InvalidMoneyError) Unwrap() error {
+	return e.Err
+}
+
+// invalidInput returns an InvalidMoneyError for text that could not be parsed as money.
+func invalidInput(input string, err error) error {
+	return &InvalidMoneyError{Input: input, Err: err, parsed: true}
+}
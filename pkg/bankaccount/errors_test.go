@@ -0,0 +1,100 @@
+package bankaccount
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestInsufficientFundsError(t *testing.T) {
+	is := is.New(t)
+	err := mustNewSavingsAccount(t, WithBalance(Money{USD, 10, 0})).Withdraw(Money{USD, 15, 0})
+	is.True(errors.Is(err, ErrInsufficientFunds))
+	is.True(!errors.Is(err, ErrCurrencyMismatch))
+	var insufficient *InsufficientFundsError
+	is.True(errors.As(err, &insufficient))
+	is.Equal(insufficient.Operation, "withdrawal")
+	is.Equal(insufficient.Requested, Money{USD, 15, 0})
+	is.Equal(insufficient.Available, Money{USD, 10, 0})
+	is.Equal(err.Error(), "withdrawal of USD 15.00 would overdraw from balance of USD 10.00")
+
+	acct := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{CAD, 5, 0}))
+	err = acct.ChargeFee(Money{CAD, 6, 0})
+	is.True(errors.As(err, &insufficient))
+	is.Equal(insufficient.Operation, "fee")
+	is.Equal(insufficient.Available, Money{CAD, 5, 0})
+}
+
+func TestCurrencyMismatchError(t *testing.T) {
+	is := is.New(t)
+	tests := []struct {
+		err       error
+		operation string
+		expected  string
+		actual    string
+	}{
+		{func() error { _, err := Money{USD, 1, 0}.Add(Money{CAD, 1, 0}); return err }(), "adding", USD, CAD},
+		{func() error { _, err := Money{USD, 1, 0}.Subtract(Money{CAD, 1, 0}); return err }(), "subtracting", USD, CAD},
+		{func() error { _, err := Money{EUR, 1, 0}.LessThan(Money{USD, 1, 0}); return err }(), "comparing", EUR, USD},
+		{SortMoney([]Money{{USD, 1, 0}, {EUR, 1, 0}}), "sorting", USD, EUR},
+		{func() error {
+			_, err := Rate{From: CAD, To: USD, Units: 1}.Convert(Money{EUR, 1, 0}, RoundHalfUp)
+			return err
+		}(), "converting it using an exchange rate from CAD to USD", CAD, EUR},
+		{mustNewSavingsAccount(t).Deposit(Money{CAD, 1, 0}), "adding", USD, CAD},
+	}
+	for _, tc := range tests {
+		is.True(errors.Is(tc.err, ErrCurrencyMismatch))
+		var mismatch *CurrencyMismatchError
+		is.True(errors.As(tc.err, &mismatch))
+		is.Equal(mismatch.Operation, tc.operation)
+		is.Equal(mismatch.Expected, tc.expected)
+		is.Equal(mismatch.Actual, tc.actual)
+	}
+	_, err := Money{USD, 1, 0}.Subtract(Money{CAD, 1, 0})
+	is.Equal(err.Error(), "you must convert CAD to USD using current exchange rates before subtracting")
+}
+
+func TestRateNotFoundError(t *testing.T) {
+	is := is.New(t)
+	_, err := CurrentRates.Rate(context.Background(), USD, "JPY")
+	is.True(errors.Is(err, ErrRateNotFound))
+	var notFound *RateNotFoundError
+	is.True(errors.As(err, &notFound))
+	is.Equal(*notFound, RateNotFoundError{From: USD, To: "JPY"})
+
+	history, err := NewHistoricalRates()
+	is.NoErr(err)
+	_, err = history.Rate(context.Background(), USD, CAD)
+	is.True(errors.As(err, &notFound)) // still found when wrapped
+	is.Equal(*notFound, RateNotFoundError{From: USD, To: CAD})
+
+	_, err = mustNewSavingsAccount(t, WithBalance(Money{CAD, 1, 0})).BalanceAsCurrency("JPY")
+	is.True(errors.Is(err, ErrRateNotFound))
+}
+
+func TestInvalidMoneyError(t *testing.T) {
+	is := is.New(t)
+	err := Money{USD, 1, -1}.Validate()
+	is.True(errors.Is(err, ErrInvalidMoney))
+	var invalid *InvalidMoneyError
+	is.True(errors.As(err, &invalid))
+	is.Equal(invalid.Money, Money{USD, 1, -1})
+	is.Equal(err.Error(), "cannot mix negative nanos (-1) and positive units (1)")
+
+	_, err = NewMoney("XYZ", 1, 0)
+	is.True(errors.Is(err, ErrInvalidMoney))
+
+	_, err = ParseMoney("USD 1.2.3")
+	is.True(errors.As(err, &invalid))
+	is.Equal(invalid.Input, "USD 1.2.3")
+
+	_, err = ParseMoney("USD 99999999999999999999")
+	is.True(errors.Is(err, ErrInvalidMoney))
+	is.True(errors.Is(err, ErrOverflow)) // the reason is unwrapped
+
+	var m Money
+	is.True(errors.Is(m.UnmarshalJSON([]byte(`{"currency_code":"USD","units":1,"nanos":-1}`)), ErrInvalidMoney))
+}
@@ -1,35 +1,262 @@
 package bankaccount
 
-const (
-	USD = "USD"
-	CAD = "CAD"
-	CNY = "CNY"
-	EUR = "EUR"
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Rate is the price of one unit of the From currency in the To currency, with the same nine decimal places of
+// precision as Money. For example, a CAD to USD rate of 0.8 is represented as Units=0 and Nanos=800,000,000.
+type Rate struct {
+	From  string
+	To    string
+	Units int64
+	Nanos int32
+	// EffectiveAt is when the rate took effect. The zero time means the rate has always been in effect. A derived
+	// rate takes effect when the most recent of the rates it was derived from did.
+	EffectiveAt time.Time
+	// Path lists the quoted rates that a derived rate was calculated from, in the order they were applied. A
+	// quoted rate whose From currency is the currency being converted to was inverted. Path is empty for quoted
+	// and identity rates.
+	Path []Rate
+}
+
+// NewRate parses a decimal rate such as "0.8" or "1,080.25" for converting from one currency to another. The
+// currency codes must be known, and the rate must be positive.
+func NewRate(from string, to string, rate string) (Rate, error) {
+	nanos, err := parseDecimal(strings.TrimSpace(rate))
+	if err != nil {
+		return Rate{}, fmt.Errorf("invalid exchange rate %q: %w", rate, err)
+	}
+	r, err := rateFromNanos(from, to, nanos)
+	if err != nil {
+		return Rate{}, err
+	}
+	return r, r.Validate()
+}
+
+func rateFromNanos(from string, to string, nanos *big.Int) (Rate, error) {
+	m, err := fromNanos(to, nanos)
+	if err != nil {
+		return Rate{}, fmt.Errorf("exchange rate from %s to %s: %w", from, to, err)
+	}
+	return Rate{From: from, To: to, Units: m.Units, Nanos: m.Nanos}, nil
+}
+
+// Validate checks that both currency codes are known and that the rate is positive.
+func (r Rate) Validate() error {
+	if _, err := LookupCurrency(r.From); err != nil {
+		return err
+	}
+	if err := (Money{r.To, r.Units, r.Nanos}).Validate(); err != nil {
+		return fmt.Errorf("invalid exchange rate from %s to %s: %w", r.From, r.To, err)
+	}
+	if r.Units <= 0 && r.Nanos <= 0 {
+		return fmt.Errorf("exchange rate from %s to %s must be positive", r.From, r.To)
+	}
+	return nil
+}
+
+func (r Rate) totalNanos() *big.Int {
+	return Money{r.To, r.Units, r.Nanos}.totalNanos()
+}
+
+// Convert returns m, which must be in the rate's From currency, converted to the To currency. Fractional nanos
+// are rounded using the given rounding mode.
+func (r Rate) Convert(m Money, mode RoundingMode) (Money, error) {
+	product, err := r.product(m)
+	if err != nil {
+		return Money{}, err
+	}
+	return fromNanos(r.To, mode.quo(product, bigBase))
+}
+
+// convertToMinorUnits returns m converted to the To currency and rounded to its minor unit using the given
+// rounding mode. The exact product is rounded once, which Convert followed by Round would not do.
+func (r Rate) convertToMinorUnits(m Money, mode RoundingMode) (Money, error) {
+	product, err := r.product(m)
+	if err != nil {
+		return Money{}, err
+	}
+	c, err := LookupCurrency(r.To)
+	if err != nil {
+		return Money{}, err
+	}
+	minorUnit := pow10(9 - c.MinorUnits)
+	rounded := mode.quo(product, new(big.Int).Mul(minorUnit, bigBase))
+	return fromNanos(r.To, rounded.Mul(rounded, minorUnit))
+}
+
+// product returns the exact product of m and the rate in nanos squared, i.e., with eighteen decimal places.
+func (r Rate) product(m Money) (*big.Int, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if m.CurrencyCode != r.From {
+		return nil, &CurrencyMismatchError{
+			Operation: fmt.Sprintf("converting it using an exchange rate from %s to %s", r.From, r.To),
+			Expected:  r.From,
+			Actual:    m.CurrencyCode,
+		}
+	}
+	return new(big.Int).Mul(m.totalNanos(), r.totalNanos()), nil
+}
+
+// String returns the rate in a form such as "CAD/USD 0.8".
+func (r Rate) String() string {
+	_, whole, fraction := Money{r.To, r.Units, r.Nanos}.digits()
+	if fraction = strings.TrimRight(fraction, "0"); fraction != "" {
+		whole += "." + fraction
+	}
+	return fmt.Sprintf("%s/%s %s", r.From, r.To, whole)
+}
+
+// ExchangeRateProvider looks up the current rate for converting from one currency to another.
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
 type exchangeRate struct {
 	from string
 	to   string
 }
 
+// ExchangeRates is a static, in-memory ExchangeRateProvider.
 type ExchangeRates struct {
-	rates map[exchangeRate]Money
-}
-
-// func (e ExchangeRates) ToUSD(money Money) (Money, error) {
-// 	conversion := exchangeRate{from: money.CurrencyCode, to: USD}
-// 	currentRate, exists := e.rates[conversion]
-// 	if !exists {
-// 		return Money{}, fmt.Errorf("current exchange rate not found for %s", money.CurrencyCode)
-// 	}
-// 	m, err := money.Multiply(currentRate.Units, currentRate.Nanos)
-// 	return m, err
-// }
-
-var CurrentRates = ExchangeRates{
-	rates: map[exchangeRate]Money{
-		{CAD, USD}: {USD, 0, 800000000},
-		{CNY, USD}: {USD, 0, 160000000},
-		{EUR, USD}: {USD, 1, 80000000},
-	},
+	rates map[exchangeRate]Rate
+	pivot string
+}
+
+// NewExchangeRates returns an ExchangeRates holding the given rates. If more than one rate is given for the
+// same pair of currencies, the last one wins.
+func NewExchangeRates(rates ...Rate) ExchangeRates {
+	e := ExchangeRates{rates: make(map[exchangeRate]Rate, len(rates))}
+	for _, r := range rates {
+		e.rates[exchangeRate{r.From, r.To}] = r
+	}
+	return e
 }
+
+// WithPivot returns a copy of e that prefers to derive cross rates through the given currency, e.g., EUR to
+// CAD through USD, when more than one equally short path is available.
+func (e ExchangeRates) WithPivot(currency string) ExchangeRates {
+	e.pivot = currency
+	return e
+}
+
+// Rate returns the rate for converting from one currency to another. If the rate was not quoted directly, it
+// is derived, in order of preference, as the identity rate when both currencies are the same, as the inverse
+// of the rate quoted in the opposite direction, or as a cross rate along the shortest path of quoted rates,
+// preferring paths through the pivot currency. Derived rates are rounded half to even to nine decimal places,
+// and report the quoted rates they were derived from in their Path. If there is no path, a RateNotFoundError is
+// returned.
+func (e ExchangeRates) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if rate, found := e.rates[exchangeRate{from, to}]; found {
+		return rate, nil
+	}
+	if from == to {
+		if _, err := LookupCurrency(from); err != nil {
+			return Rate{}, err
+		}
+		return Rate{From: from, To: to, Units: 1}, nil
+	}
+	path := e.shortestPath(from, to)
+	if path == nil {
+		return Rate{}, &RateNotFoundError{From: from, To: to}
+	}
+	// multiply the rates along the path exactly, and only round the final result
+	value := big.NewRat(1, 1)
+	currency := from
+	for _, leg := range path {
+		legValue := new(big.Rat).SetFrac(leg.totalNanos(), bigBase)
+		if leg.From == currency {
+			value.Mul(value, legValue)
+			currency = leg.To
+		} else {
+			value.Quo(value, legValue)
+			currency = leg.From
+		}
+	}
+	nanos := RoundHalfEven.quo(new(big.Int).Mul(value.Num(), bigBase), value.Denom())
+	rate, err := rateFromNanos(from, to, nanos)
+	if err != nil {
+		return Rate{}, err
+	}
+	if err := rate.Validate(); err != nil {
+		return Rate{}, err
+	}
+	for _, leg := range path {
+		if leg.EffectiveAt.After(rate.EffectiveAt) {
+			rate.EffectiveAt = leg.EffectiveAt
+		}
+	}
+	rate.Path = path
+	return rate, nil
+}
+
+// shortestPath searches for the fewest quoted rates, used either as quoted or inverted, that lead from one
+// currency to the other. It returns nil if there is no such path.
+func (e ExchangeRates) shortestPath(from string, to string) []Rate {
+	// every quoted rate can be followed in both directions, but a quoted rate is preferred over an inverse
+	neighbors := map[string]map[string]Rate{}
+	addEdge := func(from string, to string, r Rate, quoted bool) {
+		if neighbors[from] == nil {
+			neighbors[from] = map[string]Rate{}
+		}
+		if _, exists := neighbors[from][to]; !exists || quoted {
+			neighbors[from][to] = r
+		}
+	}
+	for _, r := range e.rates {
+		addEdge(r.From, r.To, r, true)
+		addEdge(r.To, r.From, r, false)
+	}
+
+	// breadth first search, visiting the pivot first and then the other currencies in alphabetical order so
+	// the result is deterministic
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		currency := queue[0]
+		queue = queue[1:]
+		if currency == to {
+			break
+		}
+		next := make([]string, 0, len(neighbors[currency]))
+		for n := range neighbors[currency] {
+			next = append(next, n)
+		}
+		sort.Slice(next, func(i, j int) bool {
+			if (next[i] == e.pivot) != (next[j] == e.pivot) {
+				return next[i] == e.pivot
+			}
+			return next[i] < next[j]
+		})
+		for _, n := range next {
+			if _, visited := previous[n]; !visited {
+				previous[n] = currency
+				queue = append(queue, n)
+			}
+		}
+	}
+	if _, reached := previous[to]; !reached {
+		return nil
+	}
+	var path []Rate
+	for currency := to; currency != from; currency = previous[currency] {
+		path = append([]Rate{neighbors[previous[currency]][currency]}, path...)
+	}
+	return path
+}
+
+// CurrentRates are the rates used by accounts that are not given their own ExchangeRateProvider. Cross rates
+// are derived through USD.
+var CurrentRates = NewExchangeRates(
+	Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000},
+	Rate{From: CNY, To: USD, Units: 0, Nanos: 160000000},
+	Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000},
+).WithPivot(USD)
@@ -0,0 +1,178 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewRate(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Rate
+	}{
+		{"0.8", Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000}},
+		{" 1.08 ", Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000}},
+		{"1,080.123456789", Rate{From: EUR, To: USD, Units: 1080, Nanos: 123456789}},
+		{"0.000000001", Rate{From: EUR, To: USD, Units: 0, Nanos: 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			is := is.New(t)
+			actual, err := NewRate(tc.expected.From, tc.expected.To, tc.input)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+}
+
+func TestNewRateErrors(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+		rate string
+	}{
+		{CAD, USD, "0"},
+		{CAD, USD, "-0.8"},
+		{CAD, USD, "0.0000000001"},
+		{CAD, USD, "abc"},
+		{"XYZ", USD, "0.8"},
+		{CAD, "XYZ", "0.8"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%s %s", tc.from, tc.to, tc.rate), func(t *testing.T) {
+			if _, err := NewRate(tc.from, tc.to, tc.rate); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestRateConvert(t *testing.T) {
+	testCases := []struct {
+		rate     Rate
+		money    Money
+		mode     RoundingMode
+		expected Money
+	}{
+		{Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000}, Money{CAD, 100, 0}, RoundHalfUp, Money{USD, 80, 0}},
+		{Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000}, Money{EUR, -100, 0}, RoundHalfUp, Money{USD, -108, 0}},
+		{Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000}, Money{EUR, 12345678901234, 567890123}, RoundHalfUp, Money{USD, 13333333213333, 333321333}},
+		{Rate{From: CAD, To: USD, Units: 0, Nanos: 500000000}, Money{CAD, 0, 1}, RoundHalfUp, Money{USD, 0, 1}},
+		{Rate{From: CAD, To: USD, Units: 0, Nanos: 500000000}, Money{CAD, 0, 1}, RoundHalfEven, Money{USD, 0, 0}},
+		{Rate{From: CAD, To: USD, Units: 0, Nanos: 500000000}, Money{CAD, 0, 1}, RoundTowardZero, Money{USD, 0, 0}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d: %s * %s", i, tc.money, tc.rate), func(t *testing.T) {
+			is := is.New(t)
+			actual, err := tc.rate.Convert(tc.money, tc.mode)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+}
+
+func TestRateConvertErrors(t *testing.T) {
+	is := is.New(t)
+	rate := Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000}
+	_, err := rate.Convert(Money{EUR, 1, 0}, RoundHalfUp)
+	is.True(err != nil) // wrong currency
+	_, err = rate.Convert(Money{CAD, 1, -1}, RoundHalfUp)
+	is.True(err != nil) // invalid money
+	_, err = Rate{From: CAD, To: USD, Units: 2, Nanos: 0}.Convert(Money{CAD, 9223372036854775807, 0}, RoundHalfUp)
+	is.True(err != nil) // overflow
+}
+
+func TestRateString(t *testing.T) {
+	is := is.New(t)
+	is.Equal(Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000}.String(), "CAD/USD 0.8")
+	is.Equal(Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000}.String(), "EUR/USD 1.08")
+	is.Equal(Rate{From: USD, To: "JPY", Units: 150, Nanos: 0}.String(), "USD/JPY 150")
+}
+
+func TestExchangeRates(t *testing.T) {
+	is := is.New(t)
+	var provider ExchangeRateProvider = NewExchangeRates(
+		Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000},
+		Rate{From: CAD, To: USD, Units: 0, Nanos: 750000000},
+	)
+	rate, err := provider.Rate(context.Background(), CAD, USD)
+	is.NoErr(err)
+	is.Equal(rate, Rate{From: CAD, To: USD, Units: 0, Nanos: 750000000}) // the last rate wins
+	_, err = provider.Rate(context.Background(), EUR, USD)
+	is.True(err != nil) // rate not found
+}
+
+func TestCurrentRatesAreValid(t *testing.T) {
+	is := is.New(t)
+	for _, rate := range CurrentRates.rates {
+		is.NoErr(rate.Validate())
+	}
+}
+
+func TestDerivedRates(t *testing.T) {
+	cadUSD := Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000}
+	cnyUSD := Rate{From: CNY, To: USD, Units: 0, Nanos: 160000000}
+	eurUSD := Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000}
+	eurCAD := Rate{From: EUR, To: CAD, Units: 1, Nanos: 500000000}
+	gbpEUR := Rate{From: "GBP", To: EUR, Units: 1, Nanos: 200000000}
+	rates := NewExchangeRates(cadUSD, cnyUSD, eurUSD, eurCAD, gbpEUR)
+	jpyCAD := Rate{From: "JPY", To: CAD, Units: 0, Nanos: 9000000}
+	jpyUSD := Rate{From: "JPY", To: USD, Units: 0, Nanos: 6750000}
+	tied := NewExchangeRates(eurUSD, eurCAD, jpyCAD, jpyUSD)
+
+	testCases := []struct {
+		rates    ExchangeRates
+		from     string
+		to       string
+		expected Rate
+	}{
+		// quoted
+		{rates, CAD, USD, cadUSD},
+		// identity
+		{rates, USD, USD, Rate{From: USD, To: USD, Units: 1}},
+		{rates, "JPY", "JPY", Rate{From: "JPY", To: "JPY", Units: 1}},
+		// inverse
+		{rates, USD, CAD, Rate{From: USD, To: CAD, Units: 1, Nanos: 250000000, Path: []Rate{cadUSD}}},
+		{rates, USD, EUR, Rate{From: USD, To: EUR, Units: 0, Nanos: 925925926, Path: []Rate{eurUSD}}},
+		// cross rates, with ties broken alphabetically without a pivot
+		{rates, CNY, CAD, Rate{From: CNY, To: CAD, Units: 0, Nanos: 200000000, Path: []Rate{cnyUSD, cadUSD}}},
+		{rates, CAD, "GBP", Rate{From: CAD, To: "GBP", Units: 0, Nanos: 555555556, Path: []Rate{eurCAD, gbpEUR}}},
+		{rates, "GBP", CNY, Rate{From: "GBP", To: CNY, Units: 8, Nanos: 100000000, Path: []Rate{gbpEUR, eurUSD, cnyUSD}}},
+		// cross rates through the pivot when there is a tie, but never a longer path
+		{tied, "JPY", EUR, Rate{From: "JPY", To: EUR, Units: 0, Nanos: 6000000, Path: []Rate{jpyCAD, eurCAD}}},
+		{tied.WithPivot(USD), "JPY", EUR, Rate{From: "JPY", To: EUR, Units: 0, Nanos: 6250000, Path: []Rate{jpyUSD, eurUSD}}},
+		{rates.WithPivot(USD), CAD, "GBP", Rate{From: CAD, To: "GBP", Units: 0, Nanos: 555555556, Path: []Rate{eurCAD, gbpEUR}}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%s pivot %q", tc.from, tc.to, tc.rates.pivot), func(t *testing.T) {
+			is := is.New(t)
+			actual, err := tc.rates.Rate(context.Background(), tc.from, tc.to)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+}
+
+func TestDerivedRateErrors(t *testing.T) {
+	is := is.New(t)
+	rates := NewExchangeRates(
+		Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000},
+		Rate{From: "JPY", To: "KRW", Units: 9, Nanos: 0},
+	)
+	_, err := rates.Rate(context.Background(), CAD, "JPY")
+	is.True(err != nil) // no path between the two groups of currencies
+	_, err = rates.Rate(context.Background(), CAD, EUR)
+	is.True(err != nil) // no rates for EUR
+	_, err = rates.Rate(context.Background(), "XYZ", "XYZ")
+	is.True(err != nil) // identity for an unknown currency
+
+	tiny := NewExchangeRates(Rate{From: "IRR", To: USD, Units: 0, Nanos: 1}, Rate{From: "VND", To: USD, Units: 0, Nanos: 1})
+	_, err = tiny.Rate(context.Background(), "IRR", "VND")
+	is.NoErr(err)
+	tiny = NewExchangeRates(Rate{From: "IRR", To: USD, Units: 0, Nanos: 1}, Rate{From: USD, To: "VND", Units: 0, Nanos: 1})
+	_, err = tiny.Rate(context.Background(), "IRR", "VND")
+	is.True(err != nil) // the derived rate rounds to zero
+}
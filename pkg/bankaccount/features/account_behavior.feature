@@ -20,17 +20,36 @@ Given I have an account with 0.00 USD
  When I deposit 5.00 USD
  Then the account balance must be 5.00 USD
 
+Scenario: Deposit fractional amounts into account
+Given I have an account with 0.00 USD
+ When I deposit 1.05 USD
+  And I deposit 0.5 USD
+ Then the account balance must be 1.55 USD
+
 @bar
 Scenario: Withdraw money from account
 Given I have an account with 11.00 USD
  When I withdraw 5.00 USD
  Then the account balance must be 6.00 USD
 
+Scenario: Transaction history
+Given I have an account with 10.00 USD
+ When I deposit 5.00 USD
+  And I withdraw 12.00 USD
+  And I try to withdraw 50.00 USD
+  And I deposit 1.50 USD
+ Then the account must have the following transactions:
+|type           |amount   |balance  |
+|opening balance|10.00 USD|10.00 USD|
+|deposit        |5.00 USD |15.00 USD|
+|withdrawal     |12.00 USD|3.00 USD |
+|deposit        |1.50 USD |4.50 USD |
+
 @priority:high
 Scenario: Attempt to overdraw account
 Given I have an account with 11.00 USD
  When I try to withdraw 50.00 USD
- Then the transaction should error
+ Then the transaction should fail with insufficient funds
 
 Scenario: Concurrent Deposits and withdrawals
 Given I have an account with 100.00 USD
@@ -60,6 +79,76 @@ Examples:
 |CNY     |16     |
 |EUR     |108    |
 
+Scenario: Converting with exchange rates for the scenario
+Given the following exchange rates:
+|from|to |rate|
+|EUR |USD|1.10|
+  And I have an account with 100.00 EUR
+ Then the account balance must convert to 110 USD
+
+Scenario Outline: Converting with inverse and cross rates
+Given I have an account with <balance>
+ Then the account balance must convert to <converted>
+
+Examples:
+|balance   |converted |
+|100.00 USD|100.00 USD|
+|80.00 USD |100.00 CAD|
+|100.00 CNY|20.00 CAD |
+
+Scenario Outline: Converting with exchange rates from a file
+Given the exchange rates in "<file>"
+  And I have an account with 100.00 GBP
+ Then the account balance must convert to 156.25 CAD
+  And the account balance must convert to 125.00 USD
+
+Examples:
+|file               |
+|testdata/rates.csv |
+|testdata/rates.json|
+
+Scenario: Converting with rates from the ECB feed
+Given the ECB publishes the following rates on 2024-01-05:
+|currency|rate|
+|USD     |1.25|
+|CAD     |1.6 |
+  And I have an account with 160.00 CAD
+ Then the account balance must convert to 100.00 EUR
+  And the account balance must convert to 125.00 USD
+
+Scenario: Converting without a known exchange rate
+Given the following exchange rates:
+|from|to |rate|
+|EUR |USD|1.10|
+  And I have an account with 100.00 CAD
+ Then the account balance must not convert to USD
+
+Scenario: Attempt to deposit another currency
+Given I have an account with 10.00 USD
+ When I try to deposit 100.00 CAD
+ Then the transaction should fail with a currency mismatch
+
+Scenario: Deposit another currency with auto conversion
+Given I have an account with 10.00 USD that converts other currencies
+ When I deposit 100.00 CAD
+ Then the account balance must be 90.00 USD
+  And the last conversion must use the rate CAD/USD 0.8
+
+Scenario: Withdraw another currency with auto conversion
+Given I have an account with 100.00 USD that converts other currencies
+ When I withdraw 50.00 EUR
+ Then the account balance must be 46.00 USD
+  And the last conversion must use the rate EUR/USD 1.08
+
+Scenario: Attempt to deposit a currency without a known exchange rate
+Given the following exchange rates:
+|from|to |rate|
+|EUR |USD|1.10|
+  And I have an account with 10.00 USD that converts other currencies
+ When I try to deposit 100.00 CAD
+ Then the transaction should fail with no exchange rate
+  And the account balance must be 10.00 USD
+
 @issue#952
 Scenario: Remittance address
 Given I have a new account
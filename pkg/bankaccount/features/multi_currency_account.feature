@@ -0,0 +1,40 @@
+Feature: Multi-Currency Account
+
+As an account holder who is paid in more than one currency, I need to be able to hold
+balances in each currency, convert between them, and see what they are worth together.
+
+Scenario: Deposit money in more than one currency
+Given I have a multi-currency account reporting in USD
+ When I deposit 100.00 CAD
+  And I deposit 50.00 EUR
+  And I deposit 15.00 USD
+ Then the account must hold:
+|balance   |
+|100.00 CAD|
+|50.00 EUR |
+|15.00 USD |
+  And the account balance must be 15.00 USD
+  And the account balance must convert to 149.00 USD
+
+Scenario: Convert between currencies within the account
+Given I have a multi-currency account reporting in CAD
+  And I deposit 100.00 CAD
+ When I convert 40.00 CAD to USD
+ Then the account must hold:
+|balance  |
+|60.00 CAD|
+|32.00 USD|
+  And the account balance must be 60.00 CAD
+  And the account balance must convert to 100.00 CAD
+
+Scenario: Attempt to convert more than the account holds
+Given I have a multi-currency account reporting in USD
+  And I deposit 10.00 CAD
+ When I try to convert 20.00 CAD to USD
+ Then the transaction should fail with insufficient funds
+
+Scenario: Attempt to withdraw from a currency the account does not hold
+Given I have a multi-currency account reporting in USD
+  And I deposit 100.00 CAD
+ When I try to withdraw 10.00 USD
+ Then the transaction should fail with insufficient funds
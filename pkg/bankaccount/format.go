@@ -0,0 +1,107 @@
+package bankaccount
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const nbsp = "\u00a0"
+
+// numberFormat describes how amounts are written in a locale. In the patterns, "¤" is replaced by the currency
+// symbol and "#" by the formatted number.
+type numberFormat struct {
+	decimal  string
+	group    string
+	positive string
+	negative string
+	// symbols that differ from the ones in the currency registry
+	symbols map[string]string
+}
+
+var locales = map[string]numberFormat{
+	"en-US": {
+		decimal:  ".",
+		group:    ",",
+		positive: "¤#",
+		negative: "-¤#",
+	},
+	"de-DE": {
+		decimal:  ",",
+		group:    ".",
+		positive: "#" + nbsp + "¤",
+		negative: "-#" + nbsp + "¤",
+	},
+	"fr-CA": {
+		decimal:  ",",
+		group:    nbsp,
+		positive: "#" + nbsp + "¤",
+		negative: "-#" + nbsp + "¤",
+		symbols: map[string]string{
+			CAD: "$",
+			USD: "$" + nbsp + "US",
+		},
+	},
+	"zh-CN": {
+		decimal:  ".",
+		group:    ",",
+		positive: "¤#",
+		negative: "-¤#",
+		symbols: map[string]string{
+			CNY:   "¥",
+			USD:   "US$",
+			"JPY": "JP¥",
+		},
+	},
+}
+
+// Format returns m formatted for the given locale, e.g., "$1,234.05" for en-US or "1.234,05 €" for de-DE.
+// The amount is rounded half up to the precision of the currency's minor unit. The supported locales are
+// en-US, de-DE, fr-CA and zh-CN.
+func (m Money) Format(locale string) (string, error) {
+	format, found := locales[locale]
+	if !found {
+		return "", fmt.Errorf("unsupported locale %q", locale)
+	}
+	c, err := LookupCurrency(m.CurrencyCode)
+	if err != nil {
+		return "", err
+	}
+	rounded, err := m.Round(RoundHalfUp)
+	if err != nil {
+		return "", err
+	}
+
+	negative, whole, fraction := rounded.digits()
+	number := groupDigits(whole, format.group)
+	if c.MinorUnits > 0 {
+		number += format.decimal + fraction[:c.MinorUnits]
+	}
+
+	symbol, found := format.symbols[c.Code]
+	if !found {
+		symbol = c.Symbol
+	}
+	pattern := format.positive
+	if negative {
+		pattern = format.negative
+	}
+	// keep symbols ending in a letter, e.g., "AED", from running into the digits
+	if r, _ := utf8.DecodeLastRuneInString(symbol); unicode.IsLetter(r) {
+		pattern = strings.Replace(pattern, "¤#", "¤"+nbsp+"#", 1)
+	}
+	return strings.NewReplacer("¤", symbol, "#", number).Replace(pattern), nil
+}
+
+// groupDigits inserts the separator between every group of three digits, counting from the right.
+func groupDigits(digits string, separator string) string {
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(separator)
+		}
+		sb.WriteRune(d)
+	}
+	return sb.String()
+}
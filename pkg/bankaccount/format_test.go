@@ -0,0 +1,86 @@
+package bankaccount
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestString(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{Money{USD, 0, 0}, "USD 0.00"},
+		{Money{USD, 1, 50000000}, "USD 1.05"},
+		{Money{USD, 1, 500000000}, "USD 1.50"},
+		{Money{USD, 1234, 0}, "USD 1234.00"},
+		{Money{USD, 0, -750000000}, "USD -0.75"},
+		{Money{USD, -1, -750000000}, "USD -1.75"},
+		{Money{USD, 0, 1}, "USD 0.000000001"},
+		{Money{USD, 1, 123400000}, "USD 1.1234"},
+		{Money{"JPY", 100, 0}, "JPY 100"},
+		{Money{"JPY", 100, 500000000}, "JPY 100.5"},
+		{Money{"KWD", 1, 500000000}, "KWD 1.500"},
+		{Money{"XYZ", 1, 500000000}, "XYZ 1.5"},
+		{Money{USD, -9223372036854775808, -999999999}, "USD -9223372036854775808.999999999"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tc.money.String(), tc.expected)
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	is := is.New(t)
+	for _, m := range []Money{{USD, 1, 50000000}, {USD, 0, -750000000}, {"JPY", 7, 0}, {EUR, -3, -1}} {
+		parsed, err := ParseMoney(m.String())
+		is.NoErr(err)
+		is.Equal(parsed, m)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		locale   string
+		expected string
+	}{
+		{Money{USD, 1234, 50000000}, "en-US", "$1,234.05"},
+		{Money{USD, -1234, -50000000}, "en-US", "-$1,234.05"},
+		{Money{USD, 0, -750000000}, "en-US", "-$0.75"},
+		{Money{USD, 1234567, 5000000}, "en-US", "$1,234,567.01"},
+		{Money{EUR, 100, 0}, "en-US", "€100.00"},
+		{Money{CAD, 100, 0}, "en-US", "CA$100.00"},
+		{Money{"JPY", 1234, 500000000}, "en-US", "¥1,235"},
+		{Money{"AED", 1, 0}, "en-US", "AED 1.00"},
+		{Money{EUR, 1234, 50000000}, "de-DE", "1.234,05 €"},
+		{Money{EUR, -1234, -50000000}, "de-DE", "-1.234,05 €"},
+		{Money{USD, 12, 0}, "de-DE", "12,00 $"},
+		{Money{CAD, 1234, 50000000}, "fr-CA", "1 234,05 $"},
+		{Money{USD, 1234, 50000000}, "fr-CA", "1 234,05 $ US"},
+		{Money{CNY, 1234, 50000000}, "zh-CN", "¥1,234.05"},
+		{Money{USD, 1234, 50000000}, "zh-CN", "US$1,234.05"},
+		{Money{"KWD", 1, 234500000}, "zh-CN", "KWD 1.235"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.locale+" "+tc.expected, func(t *testing.T) {
+			is := is.New(t)
+			actual, err := tc.money.Format(tc.locale)
+			is.NoErr(err)
+			// non-breaking spaces are used to keep amounts together
+			is.Equal(strings.ReplaceAll(actual, nbsp, " "), tc.expected)
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := Money{USD, 1, 0}.Format("xx-XX")
+	is.True(err != nil) // unsupported locale
+	_, err = Money{"XYZ", 1, 0}.Format("en-US")
+	is.True(err != nil) // unknown currency
+}
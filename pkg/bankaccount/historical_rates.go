@@ -0,0 +1,125 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HistoricalRateProvider looks up the rate for converting from one currency to another as it was at a given
+// time.
+type HistoricalRateProvider interface {
+	RateAsOf(ctx context.Context, from string, to string, t time.Time) (Rate, error)
+}
+
+// HistoricalRates stores quoted rates by currency pair and the time they took effect, so conversions can be
+// reproduced as of any point in time. It is safe for concurrent use.
+type HistoricalRates struct {
+	mu    sync.RWMutex
+	rates map[exchangeRate][]Rate // sorted by EffectiveAt
+	pivot string
+}
+
+// DailyRate is one observation in a daily series of rates, e.g., {"2024-01-05", "1.0921"}. The rate takes
+// effect at midnight UTC on the given date.
+type DailyRate struct {
+	Date string
+	Rate string
+}
+
+// NewHistoricalRates returns a HistoricalRates holding the given rates.
+func NewHistoricalRates(rates ...Rate) (*HistoricalRates, error) {
+	h := &HistoricalRates{rates: map[exchangeRate][]Rate{}}
+	if err := h.Add(rates...); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SetPivot sets the currency through which cross rates are preferably derived. See ExchangeRates.WithPivot.
+func (h *HistoricalRates) SetPivot(currency string) {
+	h.mu.Lock()
+	h.pivot = currency
+	h.mu.Unlock()
+}
+
+// Add stores the given rates. A rate for the same pair of currencies and the same effective time as an existing
+// rate replaces it. If any rate is invalid, none of them are stored.
+func (h *HistoricalRates) Add(rates ...Rate) error {
+	for _, r := range rates {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range rates {
+		pair := exchangeRate{r.From, r.To}
+		series := h.rates[pair]
+		i := sort.Search(len(series), func(i int) bool {
+			return !series[i].EffectiveAt.Before(r.EffectiveAt)
+		})
+		if i < len(series) && series[i].EffectiveAt.Equal(r.EffectiveAt) {
+			series[i] = r
+			continue
+		}
+		series = append(series, Rate{})
+		copy(series[i+1:], series[i:])
+		series[i] = r
+		h.rates[pair] = series
+	}
+	return nil
+}
+
+// ImportDailySeries stores a series of daily rates for converting from one currency to another. If any entry is
+// invalid, none of them are stored.
+func (h *HistoricalRates) ImportDailySeries(from string, to string, series []DailyRate) error {
+	rates := make([]Rate, 0, len(series))
+	for _, day := range series {
+		effectiveAt, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return fmt.Errorf("invalid date in daily %s/%s series: %w", from, to, err)
+		}
+		rate, err := NewRate(from, to, day.Rate)
+		if err != nil {
+			return fmt.Errorf("invalid rate on %s in daily %s/%s series: %w", day.Date, from, to, err)
+		}
+		rate.EffectiveAt = effectiveAt
+		rates = append(rates, rate)
+	}
+	return h.Add(rates...)
+}
+
+// AsOf returns the rates that were in effect at time t, i.e., the most recent rate for each pair of currencies
+// that took effect at or before t.
+func (h *HistoricalRates) AsOf(t time.Time) ExchangeRates {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	inEffect := []Rate{}
+	for _, series := range h.rates {
+		i := sort.Search(len(series), func(i int) bool {
+			return series[i].EffectiveAt.After(t)
+		})
+		if i > 0 {
+			inEffect = append(inEffect, series[i-1])
+		}
+	}
+	return NewExchangeRates(inEffect...).WithPivot(h.pivot)
+}
+
+// RateAsOf returns the rate for converting from one currency to another that was in effect at time t. Inverse
+// and cross rates are derived from the rates in effect at that time as described in ExchangeRates.Rate.
+func (h *HistoricalRates) RateAsOf(ctx context.Context, from string, to string, t time.Time) (Rate, error) {
+	rate, err := h.AsOf(t).Rate(ctx, from, to)
+	if err != nil {
+		return Rate{}, fmt.Errorf("as of %s: %w", t.Format(time.RFC3339), err)
+	}
+	return rate, nil
+}
+
+// Rate returns the rate currently in effect, so HistoricalRates can be used as an ExchangeRateProvider.
+func (h *HistoricalRates) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	return h.RateAsOf(ctx, from, to, time.Now())
+}
@@ -0,0 +1,148 @@
+package bankaccount
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRateAsOf(t *testing.T) {
+	h, err := NewHistoricalRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = h.ImportDailySeries(EUR, USD, []DailyRate{
+		{"2024-01-03", "1.09"},
+		{"2024-01-02", "1.10"},
+		{"2024-01-05", "1.0921"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = h.Add(Rate{From: CAD, To: USD, Units: 0, Nanos: 750000000, EffectiveAt: date("2024-01-04")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		from     string
+		to       string
+		asOf     time.Time
+		expected string
+	}{
+		{EUR, USD, date("2024-01-02"), "EUR/USD 1.1"},
+		{EUR, USD, date("2024-01-02").Add(12 * time.Hour), "EUR/USD 1.1"},
+		{EUR, USD, date("2024-01-03"), "EUR/USD 1.09"},
+		{EUR, USD, date("2024-01-04"), "EUR/USD 1.09"},
+		{EUR, USD, date("2024-06-30"), "EUR/USD 1.0921"},
+		{USD, EUR, date("2024-01-02"), "USD/EUR 0.909090909"},
+		{EUR, CAD, date("2024-01-04"), "EUR/CAD 1.453333333"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expected+" as of "+tc.asOf.String(), func(t *testing.T) {
+			is := is.New(t)
+			rate, err := h.RateAsOf(context.Background(), tc.from, tc.to, tc.asOf)
+			is.NoErr(err)
+			is.Equal(rate.String(), tc.expected)
+		})
+	}
+
+	is := is.New(t)
+	_, err = h.RateAsOf(context.Background(), EUR, USD, date("2024-01-01"))
+	is.True(err != nil) // before the first rate took effect
+	_, err = h.RateAsOf(context.Background(), EUR, CAD, date("2024-01-03"))
+	is.True(err != nil) // before the CAD rate took effect
+
+	rate, err := h.RateAsOf(context.Background(), EUR, CAD, date("2024-01-05"))
+	is.NoErr(err)
+	is.Equal(rate.EffectiveAt, date("2024-01-05")) // derived rates take effect with their most recent leg
+
+	rate, err = h.Rate(context.Background(), EUR, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.0921") // the rate in effect now
+}
+
+func TestHistoricalRatesReplaceRatesWithTheSameEffectiveTime(t *testing.T) {
+	is := is.New(t)
+	h, err := NewHistoricalRates(
+		Rate{From: EUR, To: USD, Units: 1, Nanos: 100000000, EffectiveAt: date("2024-01-02")},
+		Rate{From: EUR, To: USD, Units: 1, Nanos: 200000000, EffectiveAt: date("2024-01-02")},
+	)
+	is.NoErr(err)
+	rate, err := h.RateAsOf(context.Background(), EUR, USD, date("2024-01-02"))
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.2")
+}
+
+func TestImportDailySeriesErrors(t *testing.T) {
+	is := is.New(t)
+	h, err := NewHistoricalRates()
+	is.NoErr(err)
+	is.True(h.ImportDailySeries(EUR, USD, []DailyRate{{"2024-01-02", "1.10"}, {"01/03/2024", "1.09"}}) != nil) // bad date
+	is.True(h.ImportDailySeries(EUR, USD, []DailyRate{{"2024-01-02", "1.10"}, {"2024-01-03", "-1"}}) != nil)   // bad rate
+	is.True(h.ImportDailySeries("XYZ", USD, []DailyRate{{"2024-01-02", "1.10"}}) != nil)                       // bad currency
+	_, err = h.RateAsOf(context.Background(), EUR, USD, date("2024-01-02"))
+	is.True(err != nil) // nothing was imported
+
+	_, err = NewHistoricalRates(Rate{From: EUR, To: USD})
+	is.True(err != nil) // zero rate
+}
+
+func TestBalanceAsCurrencyAt(t *testing.T) {
+	is := is.New(t)
+	h, err := NewHistoricalRates()
+	is.NoErr(err)
+	is.NoErr(h.ImportDailySeries(EUR, USD, []DailyRate{{"2024-01-02", "1.10"}, {"2024-01-03", "1.05"}}))
+
+	acct := mustNewSavingsAccount(t, WithBalance(Money{EUR, 100, 0}), WithRateProvider(h))
+	m, err := acct.BalanceAsCurrencyAt(USD, date("2024-01-02"))
+	is.NoErr(err)
+	is.Equal(m, Money{USD, 110, 0})
+	m, err = acct.BalanceAsCurrencyAt(USD, date("2024-01-03"))
+	is.NoErr(err)
+	is.Equal(m, Money{USD, 105, 0})
+	_, err = acct.BalanceAsCurrencyAt(USD, date("2023-12-31"))
+	is.True(err != nil) // no rate yet
+
+	acct = mustNewSavingsAccount(t, WithBalance(Money{EUR, 100, 0}))
+	_, err = acct.BalanceAsCurrencyAt(USD, date("2024-01-02"))
+	is.True(err != nil) // CurrentRates has no history
+}
+
+func TestBalanceAsCurrencyAtAfterTheBalanceChanged(t *testing.T) {
+	is := is.New(t)
+	h, err := NewHistoricalRates()
+	is.NoErr(err)
+	is.NoErr(h.ImportDailySeries(EUR, USD, []DailyRate{{"2024-01-01", "1.20"}, {"2024-01-02", "1.10"}, {"2024-01-03", "1.05"}}))
+
+	// opened on 2024-01-02, then a deposit on 2024-01-03 and a withdrawal on 2024-01-04
+	acct := mustNewSavingsAccount(t, WithBalance(Money{EUR, 100, 0}), WithRateProvider(h), WithClock(clock(date("2024-01-02"))))
+	is.NoErr(acct.Deposit(Money{EUR, 50, 0}))
+	is.NoErr(acct.Withdraw(Money{EUR, 30, 0}))
+
+	testCases := []struct {
+		at       time.Time
+		expected Money
+	}{
+		{date("2024-01-01"), Money{USD, 120, 0}}, // before the account was opened, the opening balance
+		{date("2024-01-02"), Money{USD, 110, 0}},
+		{date("2024-01-03").Add(12 * time.Hour), Money{USD, 157, 500000000}},
+		{date("2024-01-05"), Money{USD, 126, 0}},
+	}
+	for _, tc := range testCases {
+		m, err := acct.BalanceAsCurrencyAt(USD, tc.at)
+		is.NoErr(err)
+		is.Equal(m, tc.expected)
+	}
+	is.Equal(acct.Balance(), Money{EUR, 120, 0}) // unchanged
+}
@@ -0,0 +1,112 @@
+package bankaccount
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultIdempotencyRetention is how long an account remembers idempotency keys by default.
+const DefaultIdempotencyRetention = 24 * time.Hour
+
+// idempotentRequest is a successful request made with an idempotency key, and the transaction it recorded.
+type idempotentRequest struct {
+	key         string
+	typ         TransactionType
+	amount      Money
+	transaction Transaction
+	expires     time.Time
+}
+
+// idempotencyKeys remembers the requests made with idempotency keys until their retention expires. It is not
+// safe for concurrent use; accounts guard it with their own lock.
+type idempotencyKeys struct {
+	retention time.Duration
+	requests  map[string]idempotentRequest
+	// keys in the order they were remembered, which is also the order they expire in
+	queue []string
+}
+
+// lookup returns the transaction recorded by an earlier request with the same key, and whether there was one.
+// It is an error to reuse a key for a different request.
+func (k *idempotencyKeys) lookup(key string, typ TransactionType, amount Money, now time.Time) (Transaction, bool, error) {
+	k.expire(now)
+	request, found := k.requests[key]
+	if !found {
+		return Transaction{}, false, nil
+	}
+	if request.typ != typ || request.amount != amount {
+		return Transaction{}, false, fmt.Errorf("idempotency key %q was already used for a %s of %s", key, request.typ, request.amount)
+	}
+	return request.transaction.copy(), true, nil
+}
+
+// remember stores the transaction recorded by a request with the given key.
+func (k *idempotencyKeys) remember(key string, typ TransactionType, amount Money, t Transaction, now time.Time) {
+	if k.requests == nil {
+		k.requests = map[string]idempotentRequest{}
+	}
+	k.requests[key] = idempotentRequest{
+		key:         key,
+		typ:         typ,
+		amount:      amount,
+		transaction: t.copy(),
+		expires:     now.Add(k.retention),
+	}
+	k.queue = append(k.queue, key)
+}
+
+// expire forgets the requests whose retention has expired.
+func (k *idempotencyKeys) expire(now time.Time) {
+	for len(k.queue) > 0 {
+		request, found := k.requests[k.queue[0]]
+		if found && now.Before(request.expires) {
+			return
+		}
+		delete(k.requests, k.queue[0])
+		k.queue = k.queue[1:]
+	}
+}
+
+// DepositIdempotent is Deposit made safe to retry. The account remembers the key of each successful request for
+// its idempotency retention, and a request that reuses a key returns the transaction the original request
+// recorded instead of depositing again. It is an error to reuse a key for a different request. A request that
+// fails is not remembered, so it can be retried with the same key.
+func (s *SavingsAccount) DepositIdempotent(key string, m Money, opts ...TransactionOption) (Transaction, error) {
+	return s.idempotent(key, TransactionDeposit, m, opts, s.deposit)
+}
+
+// WithdrawIdempotent is Withdraw made safe to retry, in the same way as DepositIdempotent.
+func (s *SavingsAccount) WithdrawIdempotent(key string, m Money, opts ...TransactionOption) (Transaction, error) {
+	return s.idempotent(key, TransactionWithdrawal, m, opts, s.withdraw)
+}
+
+// idempotent applies a request unless a request with the same key has already been applied.
+func (s *SavingsAccount) idempotent(key string, typ TransactionType, m Money, opts []TransactionOption,
+	apply func(Money, *Conversion, []TransactionOption) (Transaction, error)) (Transaction, error) {
+	if key == "" {
+		return Transaction{}, fmt.Errorf("idempotency key must not be empty")
+	}
+	s.mu.Lock()
+	t, found, err := s.idempotency.lookup(key, typ, m, s.ledger.clock()())
+	s.mu.Unlock()
+	if found || err != nil {
+		return t, err
+	}
+
+	converted, conversion, err := s.convert(m)
+	if err != nil {
+		return Transaction{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// check again, in case a request with the same key was applied while the lock was released
+	now := s.ledger.clock()()
+	if t, found, err := s.idempotency.lookup(key, typ, m, now); found || err != nil {
+		return t, err
+	}
+	if t, err = apply(converted, conversion, opts); err != nil {
+		return Transaction{}, err
+	}
+	s.idempotency.remember(key, typ, m, t, now)
+	return t, nil
+}
@@ -0,0 +1,125 @@
+package bankaccount
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDepositIdempotent(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t, WithBalance(Money{USD, 10, 0}))
+
+	original, err := acct.DepositIdempotent("key-1", Money{USD, 5, 0}, WithMemo("paycheck"))
+	is.NoErr(err)
+	is.Equal(original.Balance, Money{USD, 15, 0})
+	replayed, err := acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+	is.NoErr(err)
+	is.Equal(replayed, original) // the original result, including its memo
+	is.Equal(acct.Balance(), Money{USD, 15, 0})
+	is.Equal(len(acct.Transactions(TransactionFilter{Types: []TransactionType{TransactionDeposit}})), 1)
+
+	_, err = acct.DepositIdempotent("key-1", Money{USD, 6, 0})
+	is.True(err != nil) // the key was used for a different amount
+	_, err = acct.WithdrawIdempotent("key-1", Money{USD, 5, 0})
+	is.True(err != nil) // the key was used for a deposit
+	_, err = acct.DepositIdempotent("", Money{USD, 5, 0})
+	is.True(err != nil)
+
+	second, err := acct.DepositIdempotent("key-2", Money{USD, 5, 0})
+	is.NoErr(err)
+	is.True(second.ID != original.ID)
+	is.Equal(acct.Balance(), Money{USD, 20, 0})
+	is.NoErr(acct.Deposit(Money{USD, 5, 0})) // plain deposits are not affected
+	is.Equal(acct.Balance(), Money{USD, 25, 0})
+}
+
+func TestWithdrawIdempotent(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t, WithBalance(Money{USD, 10, 0}))
+
+	_, err := acct.WithdrawIdempotent("key-1", Money{USD, 15, 0})
+	is.True(errors.Is(err, ErrInsufficientFunds)) // would overdraw
+	is.NoErr(acct.Deposit(Money{USD, 10, 0}))
+	original, err := acct.WithdrawIdempotent("key-1", Money{USD, 15, 0})
+	is.NoErr(err) // failed requests are not remembered, so the key can be retried
+	is.Equal(original.Type, TransactionWithdrawal)
+	replayed, err := acct.WithdrawIdempotent("key-1", Money{USD, 15, 0})
+	is.NoErr(err)
+	is.Equal(replayed, original)
+	is.Equal(acct.Balance(), Money{USD, 5, 0})
+}
+
+func TestIdempotencyRetention(t *testing.T) {
+	is := is.New(t)
+	now := date("2024-01-05")
+	acct := mustNewSavingsAccount(t, WithClock(func() time.Time { return now }), WithIdempotencyRetention(time.Hour))
+
+	_, err := acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+	is.NoErr(err)
+	now = now.Add(30 * time.Minute)
+	_, err = acct.DepositIdempotent("key-2", Money{USD, 5, 0})
+	is.NoErr(err)
+	now = now.Add(29 * time.Minute)
+	_, err = acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+	is.NoErr(err)
+	is.Equal(acct.Balance(), Money{USD, 10, 0}) // still remembered
+
+	now = now.Add(time.Minute)
+	_, err = acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+	is.NoErr(err)
+	is.Equal(acct.Balance(), Money{USD, 15, 0}) // forgotten, so applied again
+	_, err = acct.DepositIdempotent("key-2", Money{USD, 5, 0})
+	is.NoErr(err)
+	is.Equal(acct.Balance(), Money{USD, 15, 0}) // still remembered
+
+	for _, retention := range []time.Duration{0, -time.Hour} {
+		acct := mustNewSavingsAccount(t, WithClock(func() time.Time { return now }), WithIdempotencyRetention(retention))
+		_, err := acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+		is.NoErr(err)
+		now = now.Add(DefaultIdempotencyRetention - time.Minute)
+		_, err = acct.DepositIdempotent("key-1", Money{USD, 5, 0})
+		is.NoErr(err)
+		is.Equal(acct.Balance(), Money{USD, 5, 0}) // the default retention is used instead
+	}
+}
+
+func TestIdempotentAutoConversion(t *testing.T) {
+	is := is.New(t)
+	rates, err := NewHistoricalRates(Rate{From: CAD, To: USD, Nanos: 800000000})
+	is.NoErr(err)
+	acct := mustNewSavingsAccount(t, WithAutoConversion(rates))
+
+	original, err := acct.DepositIdempotent("key-1", Money{CAD, 10, 0})
+	is.NoErr(err)
+	is.Equal(original.Amount, Money{USD, 8, 0})
+	is.NoErr(rates.Add(Rate{From: CAD, To: USD, Nanos: 750000000, EffectiveAt: time.Now().Add(-time.Second)}))
+	replayed, err := acct.DepositIdempotent("key-1", Money{CAD, 10, 0})
+	is.NoErr(err)
+	is.Equal(replayed, original) // converted at the original rate
+	is.Equal(acct.Balance(), Money{USD, 8, 0})
+}
+
+func TestConcurrentIdempotentDeposits(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t)
+	transactions := make([]Transaction, 50)
+	errs := make([]error, len(transactions))
+	wg := sync.WaitGroup{}
+	for i := range transactions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			transactions[i], errs[i] = acct.DepositIdempotent("retried", Money{USD, 1, 0})
+		}(i)
+	}
+	wg.Wait()
+	is.Equal(acct.Balance(), Money{USD, 1, 0}) // deposited once
+	for i, t := range transactions {
+		is.NoErr(errs[i])
+		is.Equal(t, transactions[0])
+	}
+}
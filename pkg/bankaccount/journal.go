@@ -0,0 +1,280 @@
+package bankaccount
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LedgerAccount names an account in the bank's books, as opposed to a customer's Account.
+type LedgerAccount string
+
+const (
+	// LedgerCash is the cash the bank holds, which deposits are paid into and withdrawals are paid out of.
+	LedgerCash LedgerAccount = "cash"
+	// LedgerFeeIncome is the income from fees charged to customers.
+	LedgerFeeIncome LedgerAccount = "fee income"
+	// LedgerFXGainLoss is the bank's position in each currency from converting between currencies. Its value
+	// across currencies, at current rates, is the bank's gain or loss from conversions.
+	LedgerFXGainLoss LedgerAccount = "fx gain/loss"
+)
+
+// CustomerLedgerAccount names the ledger account that holds what the bank owes the customer with the given ID.
+func CustomerLedgerAccount(id string) LedgerAccount {
+	return LedgerAccount("customer:" + id)
+}
+
+// Posting is one side of a journal entry, debiting or crediting a ledger account.
+type Posting struct {
+	Account LedgerAccount
+	Amount  Money
+	// Credit is whether the posting credits the ledger account rather than debits it.
+	Credit bool
+}
+
+// Debit returns a posting that debits the ledger account by m.
+func Debit(account LedgerAccount, m Money) Posting {
+	return Posting{Account: account, Amount: m}
+}
+
+// Credit returns a posting that credits the ledger account by m.
+func Credit(account LedgerAccount, m Money) Posting {
+	return Posting{Account: account, Amount: m, Credit: true}
+}
+
+// apply adds the posting to a balance, where debits are positive and credits negative.
+func (p Posting) apply(balance Money) (Money, error) {
+	if p.Credit {
+		return balance.Subtract(p.Amount)
+	}
+	return balance.Add(p.Amount)
+}
+
+// JournalEntry is a balanced set of postings made together. Entries are never changed once posted.
+type JournalEntry struct {
+	ID        string
+	Timestamp time.Time
+	Memo      string
+	Postings  []Posting
+}
+
+// Journal is a double-entry journal: every change to the books is posted as an entry whose debits and credits
+// balance in each currency. It is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+	now     func() time.Time
+}
+
+func NewJournal() *Journal {
+	return &Journal{now: time.Now}
+}
+
+// Post records an entry with the given postings, which must be valid and sum to zero in each currency.
+func (j *Journal) Post(memo string, postings ...Posting) (JournalEntry, error) {
+	if len(postings) < 2 {
+		return JournalEntry{}, fmt.Errorf("journal entry %q must have at least two postings", memo)
+	}
+	totals := map[string]Money{}
+	for _, p := range postings {
+		if p.Account == "" {
+			return JournalEntry{}, fmt.Errorf("journal entry %q has a posting without a ledger account", memo)
+		}
+		total, found := totals[p.Amount.CurrencyCode]
+		if !found {
+			total = Money{CurrencyCode: p.Amount.CurrencyCode}
+		}
+		total, err := p.apply(total)
+		if err != nil {
+			return JournalEntry{}, fmt.Errorf("journal entry %q: posting to %s: %w", memo, p.Account, err)
+		}
+		totals[p.Amount.CurrencyCode] = total
+	}
+	for _, total := range totals {
+		if !total.IsZero() {
+			return JournalEntry{}, fmt.Errorf("journal entry %q does not balance: debits exceed credits by %s", memo, total)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry := JournalEntry{
+		ID:        newTransactionID(),
+		Timestamp: j.now(),
+		Memo:      memo,
+		Postings:  append([]Posting(nil), postings...),
+	}
+	j.entries = append(j.entries, entry)
+	return entry, nil
+}
+
+// Entries returns every entry posted to the journal, oldest first.
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]JournalEntry, len(j.entries))
+	for i, entry := range j.entries {
+		entry.Postings = append([]Posting(nil), entry.Postings...)
+		entries[i] = entry
+	}
+	return entries
+}
+
+// Balance returns the balance of a ledger account in the given currency, which is positive when its debits
+// exceed its credits.
+func (j *Journal) Balance(account LedgerAccount, currencyCode string) (Money, error) {
+	for _, tb := range j.trialBalance() {
+		if tb.Currency == currencyCode {
+			if balance, found := tb.Balances[account]; found {
+				return balance, nil
+			}
+		}
+	}
+	if _, err := LookupCurrency(currencyCode); err != nil {
+		return Money{}, err
+	}
+	return Money{CurrencyCode: currencyCode}, nil
+}
+
+// TrialBalance is the balance of every ledger account in one currency, and their total.
+type TrialBalance struct {
+	Currency string
+	Balances map[LedgerAccount]Money
+	Total    Money
+}
+
+// TrialBalance sums every entry posted to the journal into a trial balance for each currency, sorted by currency
+// code. It returns an error if the books do not balance, i.e., if the total in any currency is not zero.
+func (j *Journal) TrialBalance() ([]TrialBalance, error) {
+	trialBalances := j.trialBalance()
+	for _, tb := range trialBalances {
+		if !tb.Total.IsZero() {
+			return trialBalances, fmt.Errorf("the books do not balance in %s: debits exceed credits by %s", tb.Currency, tb.Total)
+		}
+	}
+	return trialBalances, nil
+}
+
+func (j *Journal) trialBalance() []TrialBalance {
+	byCurrency := map[string]*TrialBalance{}
+	for _, entry := range j.Entries() {
+		for _, p := range entry.Postings {
+			tb, found := byCurrency[p.Amount.CurrencyCode]
+			if !found {
+				tb = &TrialBalance{
+					Currency: p.Amount.CurrencyCode,
+					Balances: map[LedgerAccount]Money{},
+					Total:    Money{CurrencyCode: p.Amount.CurrencyCode},
+				}
+				byCurrency[p.Amount.CurrencyCode] = tb
+			}
+			// every posting was validated when it was posted, so the only possible error is an overflow, which
+			// would take balances beyond the range of Money
+			balance, found := tb.Balances[p.Account]
+			if !found {
+				balance = Money{CurrencyCode: p.Amount.CurrencyCode}
+			}
+			tb.Balances[p.Account], _ = p.apply(balance)
+			tb.Total, _ = p.apply(tb.Total)
+		}
+	}
+	trialBalances := make([]TrialBalance, 0, len(byCurrency))
+	for _, tb := range byCurrency {
+		trialBalances = append(trialBalances, *tb)
+	}
+	sort.Slice(trialBalances, func(i, k int) bool { return trialBalances[i].Currency < trialBalances[k].Currency })
+	return trialBalances
+}
+
+// depositPostings pays cash deposited by a customer into their ledger account. A deposit that was converted
+// passes through the bank's position in each currency.
+func depositPostings(customer LedgerAccount, m Money, conversion *Conversion) []Posting {
+	if conversion == nil {
+		return []Posting{Debit(LedgerCash, m), Credit(customer, m)}
+	}
+	return []Posting{
+		Debit(LedgerCash, conversion.Original),
+		Credit(LedgerFXGainLoss, conversion.Original),
+		Debit(LedgerFXGainLoss, conversion.Converted),
+		Credit(customer, conversion.Converted),
+	}
+}
+
+// withdrawalPostings pays cash withdrawn by a customer out of their ledger account. A withdrawal that was
+// converted passes through the bank's position in each currency.
+func withdrawalPostings(customer LedgerAccount, m Money, conversion *Conversion) []Posting {
+	if conversion == nil {
+		return []Posting{Debit(customer, m), Credit(LedgerCash, m)}
+	}
+	return []Posting{
+		Debit(customer, conversion.Converted),
+		Credit(LedgerFXGainLoss, conversion.Converted),
+		Debit(LedgerFXGainLoss, conversion.Original),
+		Credit(LedgerCash, conversion.Original),
+	}
+}
+
+// conversionPostings converts part of a customer's balance in one currency to another. The spread stays in the
+// bank's position in the currency converted to, and the fee is paid to fee income.
+func conversionPostings(customer LedgerAccount, quote ConversionQuote) []Posting {
+	postings := []Posting{
+		Debit(customer, quote.Amount),
+		Credit(LedgerFXGainLoss, quote.Amount),
+		Debit(LedgerFXGainLoss, quote.Gross),
+		Credit(customer, quote.Net),
+	}
+	if !quote.Fee.IsZero() {
+		postings = append(postings, Credit(LedgerFeeIncome, quote.Fee))
+	}
+	return postings
+}
+
+// transferPostings moves money from one customer's ledger account to another's in the same journal. A transfer
+// that was converted passes through the bank's position in each currency.
+func transferPostings(from LedgerAccount, to LedgerAccount, m Money, conversion *Conversion) []Posting {
+	if conversion == nil {
+		return []Posting{Debit(from, m), Credit(to, m)}
+	}
+	return []Posting{
+		Debit(from, conversion.Original),
+		Credit(LedgerFXGainLoss, conversion.Original),
+		Debit(LedgerFXGainLoss, conversion.Converted),
+		Credit(to, conversion.Converted),
+	}
+}
+
+// reversal returns postings that undo the given postings.
+func reversal(postings []Posting) []Posting {
+	reversed := make([]Posting, len(postings))
+	for i, p := range postings {
+		p.Credit = !p.Credit
+		reversed[i] = p
+	}
+	return reversed
+}
+
+// checkFee checks that a fee charged to a customer is valid and positive, so that it cannot credit them.
+func checkFee(fee Money) error {
+	if err := fee.Validate(); err != nil {
+		return err
+	}
+	if !fee.IsPositive() {
+		return fmt.Errorf("fee of %s must be positive", fee)
+	}
+	return nil
+}
+
+// feePostings charges a fee to a customer's ledger account.
+func feePostings(customer LedgerAccount, fee Money) []Posting {
+	return []Posting{Debit(customer, fee), Credit(LedgerFeeIncome, fee)}
+}
+
+// postTo posts an entry to the journal, if there is one.
+func postTo(j *Journal, memo string, postings []Posting) error {
+	if j == nil {
+		return nil
+	}
+	_, err := j.Post(memo, postings...)
+	return err
+}
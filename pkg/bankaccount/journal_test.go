@@ -0,0 +1,123 @@
+package bankaccount
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestJournalPost(t *testing.T) {
+	is := is.New(t)
+	j := NewJournal()
+	entry, err := j.Post("deposit", Debit(LedgerCash, Money{USD, 10, 0}), Credit(CustomerLedgerAccount("alice"), Money{USD, 10, 0}))
+	is.NoErr(err)
+	is.True(entry.ID != "")
+	is.Equal(entry.Memo, "deposit")
+	is.Equal(entry.Postings, []Posting{
+		{Account: LedgerCash, Amount: Money{USD, 10, 0}},
+		{Account: "customer:alice", Amount: Money{USD, 10, 0}, Credit: true},
+	})
+
+	testCases := map[string][]Posting{
+		"one posting":         {Debit(LedgerCash, Money{USD, 10, 0})},
+		"unbalanced":          {Debit(LedgerCash, Money{USD, 10, 0}), Credit(LedgerFeeIncome, Money{USD, 9, 0})},
+		"unbalanced currency": {Debit(LedgerCash, Money{USD, 10, 0}), Credit(LedgerFeeIncome, Money{CAD, 10, 0})},
+		"no ledger account":   {Debit(LedgerCash, Money{USD, 10, 0}), Credit("", Money{USD, 10, 0})},
+		"invalid amount":      {Debit(LedgerCash, Money{"XYZ", 10, 0}), Credit(LedgerFeeIncome, Money{"XYZ", 10, 0})},
+		"overflow":            {Debit(LedgerCash, Money{USD, 9223372036854775807, 0}), Debit(LedgerCash, Money{USD, 1, 0}), Credit(LedgerFeeIncome, Money{USD, 1, 0})},
+	}
+	for name, postings := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := j.Post(name, postings...); err == nil {
+				t.Errorf("expected an error posting %v", postings)
+			}
+		})
+	}
+	is.Equal(len(j.Entries()), 1) // rejected entries are not posted
+}
+
+func TestTrialBalance(t *testing.T) {
+	is := is.New(t)
+	j := NewJournal()
+	alice, bob := CustomerLedgerAccount("alice"), CustomerLedgerAccount("bob")
+	savings := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}), WithJournal(j, "alice"), WithAutoConversion(CurrentRates))
+	wallet := mustNewMultiCurrencyAccount(t,
+		WithOpeningBalances(Money{CAD, 100, 0}),
+		WithMultiCurrencyJournal(j, "bob"),
+		WithMultiCurrencyPricing(Pricing{SpreadBasisPoints: 100, Fees: FeeSchedule{USD: {Fixed: Money{USD, 1, 0}}}}),
+	)
+
+	is.NoErr(savings.Deposit(Money{USD, 20, 0}))
+	is.NoErr(savings.Withdraw(Money{USD, 50, 0}))
+	is.NoErr(savings.ChargeFee(Money{USD, 2, 500000000}))
+	is.NoErr(savings.Deposit(Money{CAD, 10, 0})) // converted to USD 8
+	is.True(savings.ChargeFee(Money{USD, 1000, 0}) != nil)
+	_, err := wallet.Convert(CAD, USD, Money{CAD, 50, 0}) // USD 39.60 gross, USD 38.60 net
+	is.NoErr(err)
+	is.NoErr(wallet.ChargeFee(Money{CAD, 5, 0}))
+	is.NoErr(wallet.Withdraw(Money{USD, 8, 600000000}))
+
+	trialBalances, err := j.TrialBalance()
+	is.NoErr(err)
+	is.Equal(trialBalances, []TrialBalance{
+		{
+			Currency: CAD,
+			Balances: map[LedgerAccount]Money{
+				LedgerCash:       {CAD, 110, 0},
+				LedgerFXGainLoss: {CAD, -60, 0},
+				LedgerFeeIncome:  {CAD, -5, 0},
+				bob:              {CAD, -45, 0},
+			},
+			Total: Money{CAD, 0, 0},
+		},
+		{
+			Currency: USD,
+			Balances: map[LedgerAccount]Money{
+				LedgerCash:       {USD, 61, 400000000},
+				LedgerFXGainLoss: {USD, 47, 600000000},
+				LedgerFeeIncome:  {USD, -3, -500000000},
+				alice:            {USD, -75, -500000000},
+				bob:              {USD, -30, 0},
+			},
+			Total: Money{USD, 0, 0},
+		},
+	})
+
+	// what the bank owes each customer matches their account balances
+	owed, err := j.Balance(alice, USD)
+	is.NoErr(err)
+	is.Equal(owed, Money{USD, -75, -500000000})
+	is.Equal(savings.Balance(), Money{USD, 75, 500000000})
+	owed, err = j.Balance(bob, CAD)
+	is.NoErr(err)
+	is.Equal(owed, Money{CAD, -45, 0})
+	is.Equal(wallet.BalanceIn(CAD), Money{CAD, 45, 0})
+	owed, err = j.Balance(bob, EUR)
+	is.NoErr(err)
+	is.Equal(owed, Money{EUR, 0, 0})
+	_, err = j.Balance(bob, "XYZ")
+	is.True(err != nil)
+}
+
+func TestChargeFeeErrors(t *testing.T) {
+	testCases := map[string]Money{
+		"zero":     {USD, 0, 0},
+		"negative": {USD, -5, 0},
+		"invalid":  {USD, 1, -1},
+	}
+	for name, fee := range testCases {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			j := NewJournal()
+			savings := mustNewSavingsAccount(t, WithBalance(Money{USD, 10, 0}), WithJournal(j, "alice"))
+			wallet := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{USD, 10, 0}), WithMultiCurrencyJournal(j, "bob"))
+			is.True(savings.ChargeFee(fee) != nil)
+			is.True(wallet.ChargeFee(fee) != nil)
+			is.Equal(savings.Balance(), Money{USD, 10, 0})
+			is.Equal(wallet.Balance(), Money{USD, 10, 0})
+			income, err := j.Balance(LedgerFeeIncome, USD)
+			is.NoErr(err)
+			is.Equal(income, Money{USD, 0, 0}) // nothing was posted
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package bankaccount
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// TransactionType is the kind of change a Transaction made to an account's balance.
+type TransactionType int
+
+const (
+	// TransactionDeposit credits the balance.
+	TransactionDeposit TransactionType = iota + 1
+	// TransactionWithdrawal debits the balance.
+	TransactionWithdrawal
+	// TransactionFee debits a fee charged by the bank from the balance.
+	TransactionFee
+	// TransactionTransferOut debits money transferred to another account from the balance.
+	TransactionTransferOut
+	// TransactionTransferIn credits money transferred from another account to the balance.
+	TransactionTransferIn
+	// TransactionOpeningBalance records the balance an account was opened with.
+	TransactionOpeningBalance
+)
+
+func (t TransactionType) String() string {
+	switch t {
+	case TransactionDeposit:
+		return "deposit"
+	case TransactionWithdrawal:
+		return "withdrawal"
+	case TransactionFee:
+		return "fee"
+	case TransactionTransferOut:
+		return "transfer out"
+	case TransactionTransferIn:
+		return "transfer in"
+	case TransactionOpeningBalance:
+		return "opening balance"
+	}
+	return fmt.Sprintf("TransactionType(%d)", int(t))
+}
+
+// Transaction is an entry in an account's ledger, recording a change to its balance. Transactions are appended to
+// the ledger as they happen and are never changed afterwards.
+type Transaction struct {
+	// ID uniquely identifies the transaction.
+	ID   string
+	Type TransactionType
+	// Amount is the amount credited or debited, in the currency of the balance it changed.
+	Amount Money
+	// Balance is the balance that resulted from the transaction.
+	Balance Money
+	// Timestamp is when the transaction happened.
+	Timestamp time.Time
+	// Memo is an optional description of the transaction.
+	Memo string
+	// Conversion records how the amount was converted from another currency, or is nil if it was not.
+	Conversion *Conversion
+}
+
+// TransactionOption sets optional details of a transaction.
+type TransactionOption func(*Transaction)
+
+// WithMemo describes the transaction, e.g., "rent for January".
+func WithMemo(memo string) TransactionOption {
+	return func(t *Transaction) {
+		t.Memo = memo
+	}
+}
+
+// TransactionFilter selects transactions from a ledger. The zero TransactionFilter selects every transaction.
+type TransactionFilter struct {
+	// From and To select transactions with a timestamp at or after From and before To. Either may be left as the
+	// zero time to leave that end of the range open.
+	From time.Time
+	To   time.Time
+	// Types selects transactions of any of the given types. If empty, transactions of every type are selected.
+	Types []TransactionType
+	// MinAmount and MaxAmount select transactions with an amount between them, inclusive. Either may be left as
+	// the zero Money to leave that end of the range open. Transactions in a different currency are not selected.
+	MinAmount Money
+	MaxAmount Money
+}
+
+// Matches reports whether the filter selects the transaction.
+func (f TransactionFilter) Matches(t Transaction) bool {
+	if !f.From.IsZero() && t.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !t.Timestamp.Before(f.To) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, typ := range f.Types {
+			found = found || typ == t.Type
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.MinAmount != (Money{}) {
+		if c, err := t.Amount.Compare(f.MinAmount); err != nil || c < 0 {
+			return false
+		}
+	}
+	if f.MaxAmount != (Money{}) {
+		if c, err := t.Amount.Compare(f.MaxAmount); err != nil || c > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ledger is the append-only history of an account's transactions. It is not safe for concurrent use; accounts
+// guard it with their own lock.
+type ledger struct {
+	transactions []Transaction
+	now          func() time.Time
+}
+
+// append records transactions that happened together, assigning each an ID and the same timestamp, and returns
+// copies of them as they were recorded.
+func (l *ledger) append(opts []TransactionOption, transactions ...Transaction) []Transaction {
+	timestamp := l.clock()()
+	recorded := make([]Transaction, len(transactions))
+	for i, t := range transactions {
+		for _, opt := range opts {
+			opt(&t)
+		}
+		t.ID = newTransactionID()
+		t.Timestamp = timestamp
+		t = t.copy()
+		l.transactions = append(l.transactions, t)
+		recorded[i] = t.copy()
+	}
+	return recorded
+}
+
+// clock returns the ledger's clock, which is time.Now unless the account was given another.
+func (l *ledger) clock() func() time.Time {
+	if l.now == nil {
+		return time.Now
+	}
+	return l.now
+}
+
+// copy returns a copy of t that shares nothing with it that could be changed.
+func (t Transaction) copy() Transaction {
+	if t.Conversion != nil {
+		conversion := *t.Conversion
+		conversion.Rate = conversion.Rate.copy()
+		t.Conversion = &conversion
+	}
+	return t
+}
+
+// copy returns a copy of r that does not share its Path.
+func (r Rate) copy() Rate {
+	if r.Path != nil {
+		path := make([]Rate, len(r.Path))
+		for i, leg := range r.Path {
+			path[i] = leg.copy()
+		}
+		r.Path = path
+	}
+	return r
+}
+
+// query returns copies of the transactions the filter selects, oldest first.
+func (l *ledger) query(filter TransactionFilter) []Transaction {
+	transactions := []Transaction{}
+	for _, t := range l.transactions {
+		if filter.Matches(t) {
+			transactions = append(transactions, t.copy())
+		}
+	}
+	return transactions
+}
+
+func newTransactionID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("generating transaction ID: %v", err))
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,141 @@
+package bankaccount
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTransactionFilter(t *testing.T) {
+	transaction := Transaction{
+		Type:      TransactionDeposit,
+		Amount:    Money{USD, 5, 0},
+		Timestamp: date("2024-01-05"),
+	}
+	testCases := []struct {
+		filter   TransactionFilter
+		expected bool
+	}{
+		{TransactionFilter{}, true},
+		{TransactionFilter{From: date("2024-01-05")}, true},
+		{TransactionFilter{From: date("2024-01-06")}, false},
+		{TransactionFilter{To: date("2024-01-06")}, true},
+		{TransactionFilter{To: date("2024-01-05")}, false},
+		{TransactionFilter{From: date("2024-01-01"), To: date("2024-02-01")}, true},
+		{TransactionFilter{Types: []TransactionType{TransactionDeposit}}, true},
+		{TransactionFilter{Types: []TransactionType{TransactionWithdrawal}}, false},
+		{TransactionFilter{Types: []TransactionType{TransactionWithdrawal, TransactionDeposit}}, true},
+		{TransactionFilter{MinAmount: Money{USD, 5, 0}}, true},
+		{TransactionFilter{MinAmount: Money{USD, 5, 1}}, false},
+		{TransactionFilter{MaxAmount: Money{USD, 5, 0}}, true},
+		{TransactionFilter{MaxAmount: Money{USD, 4, 999999999}}, false},
+		{TransactionFilter{MinAmount: Money{USD, 1, 0}, MaxAmount: Money{USD, 10, 0}}, true},
+		{TransactionFilter{MinAmount: Money{CAD, 1, 0}}, false},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			if actual := tc.filter.Matches(transaction); actual != tc.expected {
+				t.Errorf("expected %+v to match %v but got %v", tc.filter, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTransactionTypeString(t *testing.T) {
+	is := is.New(t)
+	is.Equal(TransactionDeposit.String(), "deposit")
+	is.Equal(TransactionWithdrawal.String(), "withdrawal")
+	is.Equal(TransactionType(0).String(), "TransactionType(0)")
+}
+
+// clock returns a clock that starts at the given time and advances a day every time it is read.
+func clock(start time.Time) func() time.Time {
+	now := start.Add(-24 * time.Hour)
+	return func() time.Time {
+		now = now.Add(24 * time.Hour)
+		return now
+	}
+}
+
+func TestSavingsAccountTransactions(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t,
+		WithBalance(Money{USD, 10, 0}),
+		WithClock(clock(date("2024-01-01"))),
+		WithAutoConversion(CurrentRates),
+	)
+	is.NoErr(acct.Deposit(Money{USD, 5, 0}, WithMemo("birthday")))
+	is.NoErr(acct.Withdraw(Money{USD, 12, 0}))
+	is.True(acct.Withdraw(Money{USD, 100, 0}) != nil) // failed transactions are not recorded
+	is.NoErr(acct.Deposit(Money{CAD, 10, 0}))
+
+	transactions := acct.Transactions(TransactionFilter{})
+	is.Equal(len(transactions), 4)
+	ids := map[string]bool{}
+	for i, transaction := range transactions {
+		is.True(transaction.ID != "") // ID
+		is.True(!ids[transaction.ID]) // unique ID
+		ids[transaction.ID] = true
+		transactions[i].ID = ""
+	}
+	is.Equal(transactions, []Transaction{
+		{Type: TransactionOpeningBalance, Amount: Money{USD, 10, 0}, Balance: Money{USD, 10, 0}, Timestamp: date("2024-01-01")},
+		{Type: TransactionDeposit, Amount: Money{USD, 5, 0}, Balance: Money{USD, 15, 0}, Timestamp: date("2024-01-02"), Memo: "birthday"},
+		{Type: TransactionWithdrawal, Amount: Money{USD, 12, 0}, Balance: Money{USD, 3, 0}, Timestamp: date("2024-01-03")},
+		{
+			Type: TransactionDeposit, Amount: Money{USD, 8, 0}, Balance: Money{USD, 11, 0}, Timestamp: date("2024-01-04"),
+			Conversion: &Conversion{Original: Money{CAD, 10, 0}, Rate: Rate{From: CAD, To: USD, Nanos: 800000000}, Converted: Money{USD, 8, 0}},
+		},
+	})
+
+	// the ledger cannot be changed through the transactions it returns
+	transactions[3].Conversion.Converted = Money{USD, 1000, 0}
+	is.Equal(acct.Transactions(TransactionFilter{})[3].Conversion.Converted, Money{USD, 8, 0})
+
+	deposits := acct.Transactions(TransactionFilter{Types: []TransactionType{TransactionDeposit}, From: date("2024-01-03")})
+	is.Equal(len(deposits), 1)
+	is.Equal(deposits[0].Amount, Money{USD, 8, 0})
+	is.Equal(len(acct.Transactions(TransactionFilter{MinAmount: Money{USD, 10, 0}})), 2) // the opening balance and the withdrawal
+	is.Equal(len(acct.Transactions(TransactionFilter{To: date("2024-01-01")})), 0)
+}
+
+func TestTransactionsDoNotShareConversionPaths(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewSavingsAccount(t, WithBalance(Money{CAD, 0, 0}), WithAutoConversion(CurrentRates))
+	is.NoErr(acct.Deposit(Money{EUR, 10, 0})) // converted at a cross rate through USD
+	path := acct.Transactions(TransactionFilter{})[0].Conversion.Rate.Path
+	is.Equal(len(path), 2)
+	path[0] = Rate{}
+	is.Equal(acct.Transactions(TransactionFilter{})[0].Conversion.Rate.Path[0], Rate{From: EUR, To: USD, Units: 1, Nanos: 80000000})
+}
+
+func TestMultiCurrencyAccountTransactions(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewMultiCurrencyAccount(t, WithMultiCurrencyClock(clock(date("2024-01-01"))))
+	is.NoErr(acct.Deposit(Money{CAD, 100, 0}))
+	opened := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{USD, 5, 0}, Money{CAD, 10, 0}))
+	openingBalances := opened.Transactions(TransactionFilter{})
+	is.Equal(len(openingBalances), 2) // one for each currency
+	is.Equal(openingBalances[0].Type, TransactionOpeningBalance)
+	is.Equal(openingBalances[0].Balance, Money{CAD, 10, 0})
+	is.Equal(openingBalances[1].Balance, Money{USD, 5, 0})
+	_, err := acct.Convert(CAD, USD, Money{CAD, 50, 0}, WithMemo("travel money"))
+	is.NoErr(err)
+	is.NoErr(acct.Withdraw(Money{USD, 10, 0}))
+
+	transactions := acct.Transactions(TransactionFilter{})
+	is.Equal(len(transactions), 4)
+	conversion := &Conversion{Original: Money{CAD, 50, 0}, Rate: Rate{From: CAD, To: USD, Nanos: 800000000}, Converted: Money{USD, 40, 0}}
+	for i, expected := range []Transaction{
+		{Type: TransactionDeposit, Amount: Money{CAD, 100, 0}, Balance: Money{CAD, 100, 0}, Timestamp: date("2024-01-01")},
+		{Type: TransactionWithdrawal, Amount: Money{CAD, 50, 0}, Balance: Money{CAD, 50, 0}, Timestamp: date("2024-01-02"), Memo: "travel money", Conversion: conversion},
+		{Type: TransactionDeposit, Amount: Money{USD, 40, 0}, Balance: Money{USD, 40, 0}, Timestamp: date("2024-01-02"), Memo: "travel money", Conversion: conversion},
+		{Type: TransactionWithdrawal, Amount: Money{USD, 10, 0}, Balance: Money{USD, 30, 0}, Timestamp: date("2024-01-03")},
+	} {
+		expected.ID = transactions[i].ID
+		is.Equal(transactions[i], expected)
+	}
+	is.Equal(len(acct.Transactions(TransactionFilter{MinAmount: Money{USD, 1, 0}})), 2) // only transactions in USD
+}
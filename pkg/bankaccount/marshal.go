@@ -0,0 +1,93 @@
+package bankaccount
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// moneyFields has the same fields and tags as Money but none of its methods, so that encoding/json can be used
+// for the object form without recursing into Money's own marshalers.
+type moneyFields Money
+
+// MarshalJSON encodes m in the same object form used by google.type.Money, e.g.,
+// {"currency_code":"USD","units":12,"nanos":340000000}. The zero Money, which leaves a field such as
+// Fee.Minimum unset, is encoded as null. Like MarshalText, it returns an error for any other invalid Money.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m == (Money{}) {
+		return []byte("null"), nil
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(moneyFields(m))
+}
+
+// UnmarshalJSON decodes either the object form written by MarshalJSON or a string such as "USD 12.34" in any
+// format accepted by ParseMoney. An error is returned if the decoded value is not a valid Money. As for other
+// values, null leaves m unchanged, so an unset Money encoded by MarshalJSON is decoded as the zero Money.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return m.UnmarshalText([]byte(s))
+	}
+	var fields moneyFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	decoded := Money(fields)
+	if err := decoded.Validate(); err != nil {
+		return fmt.Errorf("invalid money %s: %w", data, err)
+	}
+	*m = decoded
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler using the canonical form returned by String.
+func (m Money) MarshalText() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting any format accepted by ParseMoney.
+func (m *Money) UnmarshalText(text []byte) error {
+	decoded, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = decoded
+	return nil
+}
+
+// Value implements driver.Valuer so m can be stored in a text column using the canonical form returned by
+// String.
+func (m Money) Value() (driver.Value, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner, accepting text in any format accepted by ParseMoney.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return m.UnmarshalText([]byte(v))
+	case []byte:
+		return m.UnmarshalText(v)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into money")
+	}
+	return fmt.Errorf("cannot scan %T into money", src)
+}
@@ -0,0 +1,150 @@
+package bankaccount
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var (
+	_ json.Marshaler           = Money{}
+	_ json.Unmarshaler         = &Money{}
+	_ encoding.TextMarshaler   = Money{}
+	_ encoding.TextUnmarshaler = &Money{}
+	_ driver.Valuer            = Money{}
+	_ sql.Scanner              = &Money{}
+)
+
+func TestMarshalJSON(t *testing.T) {
+	is := is.New(t)
+	data, err := json.Marshal(Money{USD, 12, 340000000})
+	is.NoErr(err)
+	is.Equal(string(data), `{"currency_code":"USD","units":12,"nanos":340000000}`)
+
+	data, err = json.Marshal(struct {
+		Balance Money `json:"balance"`
+	}{Money{USD, -1, 0}})
+	is.NoErr(err)
+	is.Equal(string(data), `{"balance":{"currency_code":"USD","units":-1}}`)
+
+	for _, invalid := range []Money{{USD, 1, -1}, {"XYZ", 1, 0}, {CurrencyCode: "", Units: 1}} {
+		_, err = json.Marshal(invalid)
+		is.True(errors.Is(err, ErrInvalidMoney)) // rejected like MarshalText
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Money
+	}{
+		{`{"currency_code":"USD","units":12,"nanos":340000000}`, Money{USD, 12, 340000000}},
+		{`{"currency_code":"USD","nanos":-750000000}`, Money{USD, 0, -750000000}},
+		{`{"currency_code":"JPY","units":100}`, Money{"JPY", 100, 0}},
+		{`"USD 12.34"`, Money{USD, 12, 340000000}},
+		{` "$-0.75" `, Money{USD, 0, -750000000}},
+		{`"1.05 EUR"`, Money{EUR, 1, 50000000}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			is := is.New(t)
+			var actual Money
+			is.NoErr(json.Unmarshal([]byte(tc.input), &actual))
+			is.Equal(actual, tc.expected)
+		})
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	testCases := []string{
+		`{"currency_code":"USD","units":1,"nanos":-1}`,
+		`{"currency_code":"USD","units":-1,"nanos":1}`,
+		`{"currency_code":"USD","units":1,"nanos":1000000000}`,
+		`{"currency_code":"XYZ","units":1}`,
+		`{"currency_code":"USD","units":"1"}`,
+		`"USD 1.2.3"`,
+		`"12.34"`,
+		`12.34`,
+	}
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			m := Money{USD, 5, 0}
+			if err := json.Unmarshal([]byte(input), &m); err == nil {
+				t.Errorf("expected an error decoding %s", input)
+			}
+			if m != (Money{USD, 5, 0}) {
+				t.Errorf("money was modified by a failed decode: %s", m)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	is := is.New(t)
+	type statement struct {
+		Balances map[string]Money `json:"balances"`
+	}
+	expected := statement{map[string]Money{"savings": {USD, 1, 50000000}, "checking": {EUR, 0, -1}}}
+	data, err := json.Marshal(expected)
+	is.NoErr(err)
+	var actual statement
+	is.NoErr(json.Unmarshal(data, &actual))
+	is.Equal(actual, expected)
+}
+
+func TestJSONRoundTripUnsetMoney(t *testing.T) {
+	is := is.New(t)
+	fee := Fee{BasisPoints: 50, Fixed: Money{USD, 1, 0}} // Minimum is unset
+	data, err := json.Marshal(fee)
+	is.NoErr(err)
+	is.Equal(string(data), `{"BasisPoints":50,"Fixed":{"currency_code":"USD","units":1},"Minimum":null}`)
+	var decodedFee Fee
+	is.NoErr(json.Unmarshal(data, &decodedFee))
+	is.Equal(decodedFee, fee)
+
+	data, err = json.Marshal(Transaction{})
+	is.NoErr(err)
+	var decoded Transaction
+	is.NoErr(json.Unmarshal(data, &decoded))
+	is.Equal(decoded, Transaction{})
+	_, err = json.Marshal(TransactionFilter{})
+	is.NoErr(err)
+}
+
+func TestText(t *testing.T) {
+	is := is.New(t)
+	text, err := Money{USD, -1, -50000000}.MarshalText()
+	is.NoErr(err)
+	is.Equal(string(text), "USD -1.05")
+	var m Money
+	is.NoErr(m.UnmarshalText(text))
+	is.Equal(m, Money{USD, -1, -50000000})
+
+	_, err = Money{USD, 1, -1}.MarshalText()
+	is.True(err != nil) // invalid money
+	is.True(m.UnmarshalText([]byte("USD")) != nil)
+}
+
+func TestSQL(t *testing.T) {
+	is := is.New(t)
+	value, err := Money{EUR, 1234, 500000000}.Value()
+	is.NoErr(err)
+	is.Equal(value, "EUR 1234.50")
+
+	var m Money
+	is.NoErr(m.Scan("EUR 1234.50"))
+	is.Equal(m, Money{EUR, 1234, 500000000})
+	is.NoErr(m.Scan([]byte("USD 1")))
+	is.Equal(m, Money{USD, 1, 0})
+
+	is.True(m.Scan(nil) != nil)         // NULL
+	is.True(m.Scan(int64(1)) != nil)    // unsupported type
+	is.True(m.Scan("not money") != nil) // invalid text
+	_, err = Money{"XYZ", 1, 0}.Value()
+	is.True(err != nil) // invalid money
+}
@@ -6,14 +6,21 @@ package bankaccount
 // purely for the purpose of being able to demostrate some tests.
 
 import (
+	"errors"
 	"fmt"
-	"math"
+	"math/big"
+	"sort"
+	"strings"
 )
 
 const (
 	maxNanos = 999999999
 )
 
+// ErrOverflow is returned when the result of an operation cannot be represented as a Money, i.e., when the
+// whole units would no longer fit into an int64.
+var ErrOverflow = errors.New("amount is out of range for money")
+
 type Money struct {
 
 	// The three-letter currency code defined in ISO 4217.
@@ -33,62 +40,194 @@ type Money struct {
 }
 
 func NewMoney(currencyCode string, units int64, nanos int32) (Money, error) {
-	if units < 0 && nanos > 0 {
-		return Money{}, fmt.Errorf("cannot mix negative units and positive nanos")
+	m := Money{CurrencyCode: currencyCode, Units: units, Nanos: nanos}
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// Validate checks that m has a known currency code and that its units and nanos follow the rules documented
+// on the Money fields. Struct literals are not checked when they are created, so every arithmetic method
+// validates its inputs before using them.
+// An invalid Money is reported as an InvalidMoneyError.
+func (m Money) Validate() error {
+	var err error
+	switch {
+	case m.Nanos < -maxNanos || m.Nanos > maxNanos:
+		err = fmt.Errorf("nanos %d must be between -%d and %d", m.Nanos, maxNanos, maxNanos)
+	case m.Units < 0 && m.Nanos > 0:
+		err = fmt.Errorf("cannot mix negative units (%d) and positive nanos (%d)", m.Units, m.Nanos)
+	case m.Nanos < 0 && m.Units > 0:
+		err = fmt.Errorf("cannot mix negative nanos (%d) and positive units (%d)", m.Nanos, m.Units)
+	}
+	if _, lookupErr := LookupCurrency(m.CurrencyCode); lookupErr != nil {
+		err = lookupErr
 	}
-	if nanos < 0 && units > 0 {
-		return Money{}, fmt.Errorf("cannot mix negative nanos and positive units")
+	if err != nil {
+		return &InvalidMoneyError{Money: m, Err: err}
 	}
-	return Money{CurrencyCode: currencyCode, Units: units, Nanos: nanos}, nil
+	return nil
+}
+
+// Normalize folds any nanos beyond +/-999,999,999 into the units and makes the signs of the units and nanos
+// agree, e.g., 1 unit and -250,000,000 nanos becomes 0 units and 750,000,000 nanos. The currency code is not
+// checked, so call Validate on the result if needed.
+func (m Money) Normalize() (Money, error) {
+	return fromNanos(m.CurrencyCode, m.totalNanos())
 }
 
-var base = int64(maxNanos + 1)
+var (
+	base    = int64(maxNanos + 1)
+	bigBase = big.NewInt(base)
+)
 
-// handle nanos as int64, borrowing/carrying over to units as needed
-func carry(totalUnits int64, totalNanos int64) (int64, int32) {
-	additionalUnits := totalNanos / base
-	remainingNanos := totalNanos % base
-	return totalUnits + additionalUnits, int32(remainingNanos)
+// checkOperands validates both operands of a binary operation and checks that they share a currency, returning
+// a CurrencyMismatchError if they do not. The operation is used to describe what the caller was trying to do in
+// the error message.
+func (m Money) checkOperands(money Money, operation string) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if err := money.Validate(); err != nil {
+		return err
+	}
+	if m.CurrencyCode != money.CurrencyCode {
+		return &CurrencyMismatchError{Operation: operation, Expected: m.CurrencyCode, Actual: money.CurrencyCode}
+	}
+	return nil
 }
 
 func (m Money) Add(money Money) (Money, error) {
-	if m.CurrencyCode != money.CurrencyCode {
-		return Money{}, fmt.Errorf("you must convert values to common currency code using current exchange rates before adding")
+	if err := m.checkOperands(money, "adding"); err != nil {
+		return Money{}, err
 	}
-	units, nanos := carry(m.Units+money.Units, int64(m.Nanos)+int64(money.Nanos))
-	return Money{m.CurrencyCode, units, nanos}, nil
+	total := m.totalNanos()
+	return fromNanos(m.CurrencyCode, total.Add(total, money.totalNanos()))
 }
 
 func (m Money) Subtract(money Money) (Money, error) {
-	negative := Money{
-		CurrencyCode: money.CurrencyCode,
-		Units:        money.Units * -1,
-		Nanos:        money.Nanos * -1,
+	if err := m.checkOperands(money, "subtracting"); err != nil {
+		return Money{}, err
 	}
-	result, err := m.Add(negative)
-	return result, err
+	total := m.totalNanos()
+	return fromNanos(m.CurrencyCode, total.Sub(total, money.totalNanos()))
 }
 
-// Take an input x and multiply it by m * 10 ^ n, where m is a whole number.
-// Return the quotient and remainder of the result after dividing by 10 ^ n.
-func applyFactorOfTen(x int64, m int, n int) (int64, int64) {
-	factor := math.Pow10(n)
-	product := float64(x) * float64(m) * factor
-	asNanos := product * float64(base)
-	i := int64(asNanos)
-	return i / base, i % base
+// totalNanos returns the amount of m expressed as a single (arbitrarily large) number of nanos.
+func (m Money) totalNanos() *big.Int {
+	total := new(big.Int).Mul(big.NewInt(m.Units), bigBase)
+	return total.Add(total, big.NewInt(int64(m.Nanos)))
 }
 
-func (m Money) Multiply(mantissa int, exponent int) Money {
-	wholeUnits, fractionalUnits := applyFactorOfTen(m.Units, mantissa, exponent)
-	wholeNanos, fractionalNanos := applyFactorOfTen(int64(m.Nanos), mantissa, exponent)
-	totalNanos := fractionalUnits + wholeNanos
-	// round up nanos if necessary
-	if fractionalNanos >= base/2 {
-		totalNanos += 1
+// fromNanos splits a total number of nanos back into units and nanos. Since the quotient and remainder are
+// truncated towards zero, the units and nanos always share the same sign. ErrOverflow is returned if the units
+// do not fit into an int64.
+func fromNanos(currencyCode string, total *big.Int) (Money, error) {
+	units, nanos := new(big.Int).QuoRem(total, bigBase, new(big.Int))
+	if !units.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s %s nanos", ErrOverflow, total, currencyCode)
 	}
-	units, nanos := carry(wholeUnits, totalNanos)
-	return Money{m.CurrencyCode, units, nanos}
+	return Money{currencyCode, units.Int64(), int32(nanos.Int64())}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Multiply returns m * mantissa * 10 ^ exponent. The product is calculated exactly, and any fractional nanos
+// are rounded half away from zero. ErrOverflow is returned if the result does not fit into a Money.
+func (m Money) Multiply(mantissa int, exponent int) (Money, error) {
+	return m.MultiplyWithRounding(mantissa, exponent, RoundHalfUp)
+}
+
+// MultiplyWithRounding returns m * mantissa * 10 ^ exponent, rounding any fractional nanos using the given
+// rounding mode. ErrOverflow is returned if the result does not fit into a Money.
+func (m Money) MultiplyWithRounding(mantissa int, exponent int, mode RoundingMode) (Money, error) {
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	product := new(big.Int).Mul(m.totalNanos(), big.NewInt(int64(mantissa)))
+	if exponent >= 0 {
+		return fromNanos(m.CurrencyCode, product.Mul(product, pow10(exponent)))
+	}
+	return fromNanos(m.CurrencyCode, mode.quo(product, pow10(-exponent)))
+}
+
+// Divide splits m into n equal parts. It returns the amount of each part along with the nanos left over that
+// could not be divided evenly, so that quotient * n + remainder always equals m.
+func (m Money) Divide(n int) (Money, Money, error) {
+	if err := m.Validate(); err != nil {
+		return Money{}, Money{}, err
+	}
+	if n == 0 {
+		return Money{}, Money{}, fmt.Errorf("cannot divide money by zero")
+	}
+	quotient, remainder := new(big.Int).QuoRem(m.totalNanos(), big.NewInt(int64(n)), new(big.Int))
+	q, err := fromNanos(m.CurrencyCode, quotient)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	r, err := fromNanos(m.CurrencyCode, remainder)
+	return q, r, err
+}
+
+// Allocate distributes m across len(ratios) parts in proportion to the given ratios. Nanos that cannot be
+// divided exactly are handed out one at a time to the parts with the largest remainders, so the parts always
+// sum back to m.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("at least one ratio is required to allocate money")
+	}
+	sum := new(big.Int)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("cannot allocate money using negative ratio %d", ratio)
+		}
+		sum.Add(sum, big.NewInt(int64(ratio)))
+	}
+	if sum.Sign() == 0 {
+		return nil, fmt.Errorf("cannot allocate money when all ratios are zero")
+	}
+
+	total := m.totalNanos()
+	shares := make([]*big.Int, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	allocated := new(big.Int)
+	for i, ratio := range ratios {
+		product := new(big.Int).Mul(total, big.NewInt(int64(ratio)))
+		shares[i], remainders[i] = product.QuoRem(product, sum, new(big.Int))
+		remainders[i].Abs(remainders[i])
+		allocated.Add(allocated, shares[i])
+	}
+
+	// the leftover is always fewer nanos than there are parts
+	leftover := new(big.Int).Sub(total, allocated)
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]].Cmp(remainders[order[j]]) > 0
+	})
+	step := big.NewInt(int64(leftover.Sign()))
+	for i := 0; leftover.Sign() != 0; i++ {
+		shares[order[i]].Add(shares[order[i]], step)
+		leftover.Sub(leftover, step)
+	}
+
+	parts := make([]Money, len(ratios))
+	for i, share := range shares {
+		part, err := fromNanos(m.CurrencyCode, share)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = part
+	}
+	return parts, nil
 }
 
 func (m Money) IsNegative() bool {
@@ -99,6 +238,32 @@ func (m1 Money) IsEqual(m2 Money) bool {
 	return m1.CurrencyCode == m2.CurrencyCode && m1.Units == m2.Units && m1.Nanos == m2.Nanos
 }
 
+// String returns m in a canonical form such as "USD 1.05" or "USD -0.75". The fraction is shown with at least
+// as many digits as the currency's minor unit, and with more only when needed to represent m exactly.
 func (m Money) String() string {
-	return fmt.Sprintf("%s %d.%d", m.CurrencyCode, m.Units, m.Nanos)
+	minDigits := 0
+	if c, err := LookupCurrency(m.CurrencyCode); err == nil {
+		minDigits = c.MinorUnits
+	}
+	negative, whole, fraction := m.digits()
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) < minDigits {
+		fraction += strings.Repeat("0", minDigits-len(fraction))
+	}
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	if fraction == "" {
+		return fmt.Sprintf("%s %s%s", m.CurrencyCode, sign, whole)
+	}
+	return fmt.Sprintf("%s %s%s.%s", m.CurrencyCode, sign, whole, fraction)
+}
+
+// digits returns the sign of m, its whole units as a decimal string, and its nanos as nine decimal digits.
+func (m Money) digits() (bool, string, string) {
+	total := m.totalNanos()
+	negative := total.Sign() < 0
+	whole, fraction := new(big.Int).QuoRem(total.Abs(total), bigBase, new(big.Int))
+	return negative, whole.String(), fmt.Sprintf("%09d", fraction.Int64())
 }
@@ -1,8 +1,11 @@
 package bankaccount
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
+	"testing/quick"
 
 	"github.com/matryer/is"
 )
@@ -30,7 +33,7 @@ func (tc additionTestCase) RunAdd(t *testing.T) {
 	if tc.expectedError != nil {
 		if err == nil {
 			t.Errorf("did not get the expected error")
-		} else {
+		} else if !errors.Is(err, tc.expectedError) {
 			is.Equal(err.Error(), tc.expectedError.Error())
 		}
 	} else {
@@ -47,7 +50,7 @@ func (tc additionTestCase) RunSubtract(t *testing.T) {
 	if tc.expectedError != nil {
 		if err == nil {
 			t.Errorf("did not get the expected error")
-		} else {
+		} else if !errors.Is(err, tc.expectedError) {
 			is.Equal(err.Error(), tc.expectedError.Error())
 		}
 	} else {
@@ -60,7 +63,7 @@ func (tc additionTestCase) RunSubtract(t *testing.T) {
 
 func TestAdd(t *testing.T) {
 	testCases := []additionTestCase{
-		{Money{USD, 0, 0}, Money{CAD, 0, 0}, Money{}, fmt.Errorf("you must convert values to common currency code using current exchange rates before adding")},
+		{Money{USD, 0, 0}, Money{CAD, 0, 0}, Money{}, ErrCurrencyMismatch},
 		newTestCase(Money{USD, 0, 0}, Money{USD, 0, 0}, Money{USD, 0, 0}),
 		newTestCase(Money{USD, 0, 0}, Money{USD, 1, 1}, Money{USD, 1, 1}),
 		newTestCase(Money{USD, 0, 0}, Money{USD, -1, -1}, Money{USD, -1, -1}),
@@ -75,6 +78,17 @@ func TestAdd(t *testing.T) {
 		newTestCase(Money{USD, 1, 500000000}, Money{USD, -1, -500000000}, Money{USD, 0, 0}),
 		newTestCase(Money{USD, 1, 500000000}, Money{USD, -1, -600000000}, Money{USD, 0, -100000000}),
 		newTestCase(Money{USD, 1, 1}, Money{USD, -10, -999999999}, Money{USD, -9, -999999998}),
+		newTestCase(Money{USD, 2, 0}, Money{USD, 0, -500000000}, Money{USD, 1, 500000000}),
+		newTestCase(Money{USD, -2, 0}, Money{USD, 0, 500000000}, Money{USD, -1, -500000000}),
+		// boundaries
+		newTestCase(Money{USD, math.MaxInt64, 0}, Money{USD, 0, maxNanos}, Money{USD, math.MaxInt64, maxNanos}),
+		newTestCase(Money{USD, math.MaxInt64, maxNanos}, Money{USD, math.MinInt64, 0}, Money{USD, 0, -1}),
+		newTestCase(Money{USD, math.MinInt64, 0}, Money{USD, 0, -maxNanos}, Money{USD, math.MinInt64, -maxNanos}),
+		{Money{USD, math.MaxInt64, 0}, Money{USD, 1, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MaxInt64, maxNanos}, Money{USD, 0, 1}, Money{}, ErrOverflow},
+		{Money{USD, math.MaxInt64 / 2, 0}, Money{USD, math.MaxInt64/2 + 2, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MinInt64, 0}, Money{USD, -1, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MinInt64, -maxNanos}, Money{USD, 0, -1}, Money{}, ErrOverflow},
 	}
 	for i, tc := range testCases {
 		t.Run(tc.Name(i), tc.RunAdd)
@@ -83,7 +97,7 @@ func TestAdd(t *testing.T) {
 
 func TestSubtract(t *testing.T) {
 	testCases := []additionTestCase{
-		{Money{USD, 0, 0}, Money{CAD, 0, 0}, Money{}, fmt.Errorf("you must convert values to common currency code using current exchange rates before adding")},
+		{Money{USD, 0, 0}, Money{CAD, 0, 0}, Money{}, ErrCurrencyMismatch},
 		newTestCase(Money{USD, 0, 0}, Money{USD, 0, 0}, Money{USD, 0, 0}),
 		newTestCase(Money{USD, 0, 0}, Money{USD, 1, 1}, Money{USD, -1, -1}),
 		newTestCase(Money{USD, 0, 0}, Money{USD, -1, -1}, Money{USD, 1, 1}),
@@ -98,6 +112,15 @@ func TestSubtract(t *testing.T) {
 		newTestCase(Money{USD, 1, 500000000}, Money{USD, -1, -500000000}, Money{USD, 3, 0}),
 		newTestCase(Money{USD, 1, 500000000}, Money{USD, -1, -600000000}, Money{USD, 3, 100000000}),
 		newTestCase(Money{USD, 1, 1}, Money{USD, 10, 999999999}, Money{USD, -9, -999999998}),
+		newTestCase(Money{USD, 2, 0}, Money{USD, 0, 500000000}, Money{USD, 1, 500000000}),
+		// boundaries
+		newTestCase(Money{USD, math.MaxInt64, maxNanos}, Money{USD, 0, maxNanos}, Money{USD, math.MaxInt64, 0}),
+		newTestCase(Money{USD, -1, 0}, Money{USD, math.MaxInt64, 0}, Money{USD, math.MinInt64, 0}),
+		newTestCase(Money{USD, 0, 0}, Money{USD, math.MaxInt64, maxNanos}, Money{USD, -math.MaxInt64, -maxNanos}),
+		{Money{USD, 0, 0}, Money{USD, math.MinInt64, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MaxInt64, 0}, Money{USD, -1, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MinInt64, 0}, Money{USD, 1, 0}, Money{}, ErrOverflow},
+		{Money{USD, math.MinInt64, -maxNanos}, Money{USD, 0, 1}, Money{}, ErrOverflow},
 	}
 	for i, tc := range testCases {
 		t.Run(tc.Name(i), tc.RunSubtract)
@@ -118,7 +141,8 @@ func (tc multiplicationTestCase) Name(i int) string {
 
 func (tc multiplicationTestCase) Run(t *testing.T) {
 	is := is.NewRelaxed(t)
-	actual := tc.money.Multiply(tc.mantissa, tc.exponent)
+	actual, err := tc.money.Multiply(tc.mantissa, tc.exponent)
+	is.NoErr(err)
 	is.Equal(actual.CurrencyCode, tc.expected.CurrencyCode) // CurrencyCode
 	is.Equal(actual.Units, tc.expected.Units)               // Units
 	is.Equal(actual.Nanos, tc.expected.Nanos)               // Nanos
@@ -163,12 +187,41 @@ func TestMultiply(t *testing.T) {
 		{Money{USD, 0, 1}, 6, -1, Money{USD, 0, 1}},
 		{Money{USD, 0, 1}, 5, -1, Money{USD, 0, 1}},
 		{Money{USD, 0, 1}, 2, -1, Money{USD, 0, 0}},
+		{Money{USD, 0, -1}, 6, -1, Money{USD, 0, -1}},
+		{Money{USD, 0, -1}, 5, -1, Money{USD, 0, -1}},
+		{Money{USD, 0, -1}, 2, -1, Money{USD, 0, 0}},
+		// large balances that cannot be represented exactly as a float64
+		{Money{USD, 123456789012345678, 987654321}, 1, 0, Money{USD, 123456789012345678, 987654321}},
+		{Money{USD, 9007199254740993, 1}, 1, 0, Money{USD, 9007199254740993, 1}},
+		{Money{USD, 9007199254740993, 1}, 3, 0, Money{USD, 27021597764222979, 3}},
+		{Money{USD, 10000000000, 0}, 800000000, -9, Money{USD, 8000000000, 0}},
+		{Money{USD, 12345678901234, 567890123}, 1080000000, -9, Money{USD, 13333333213333, 333321333}},
+		{Money{USD, math.MaxInt64, maxNanos}, 1, 0, Money{USD, math.MaxInt64, maxNanos}},
+		{Money{USD, math.MaxInt64, maxNanos}, -1, 0, Money{USD, -math.MaxInt64, -maxNanos}},
+		{Money{USD, math.MaxInt64, maxNanos}, 5, -1, Money{USD, 4611686018427387904, 0}},
 	}
 	for i, tc := range testCases {
 		t.Run(tc.Name(i), tc.Run)
 	}
 }
 
+func TestMultiplyOutOfRange(t *testing.T) {
+	testCases := []multiplicationTestCase{
+		{Money{USD, math.MaxInt64, 0}, 2, 0, Money{}},
+		{Money{USD, math.MaxInt64, maxNanos}, 11, -1, Money{}},
+		{Money{USD, math.MinInt64, 0}, -1, 0, Money{}},
+		{Money{USD, 1, 0}, 1, 19, Money{}},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.Name(i), func(t *testing.T) {
+			_, err := tc.money.Multiply(tc.mantissa, tc.exponent)
+			if !errors.Is(err, ErrOverflow) {
+				t.Errorf("expected ErrOverflow but got %v", err)
+			}
+		})
+	}
+}
+
 func TestIsNegative(t *testing.T) {
 	testCases := []struct {
 		money    Money
@@ -187,3 +240,220 @@ func TestIsNegative(t *testing.T) {
 		is.Equal(tc.money.IsNegative(), tc.expected)
 	}
 }
+
+func TestDivide(t *testing.T) {
+	testCases := []struct {
+		money     Money
+		n         int
+		quotient  Money
+		remainder Money
+	}{
+		{Money{USD, 10, 0}, 2, Money{USD, 5, 0}, Money{USD, 0, 0}},
+		{Money{USD, 10, 0}, 3, Money{USD, 3, 333333333}, Money{USD, 0, 1}},
+		{Money{USD, -10, 0}, 3, Money{USD, -3, -333333333}, Money{USD, 0, -1}},
+		{Money{USD, 10, 0}, -3, Money{USD, -3, -333333333}, Money{USD, 0, 1}},
+		{Money{USD, 0, 5}, 10, Money{USD, 0, 0}, Money{USD, 0, 5}},
+		{Money{USD, math.MaxInt64, maxNanos}, 1, Money{USD, math.MaxInt64, maxNanos}, Money{USD, 0, 0}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			quotient, remainder, err := tc.money.Divide(tc.n)
+			is.NoErr(err)
+			is.Equal(quotient, tc.quotient)   // quotient
+			is.Equal(remainder, tc.remainder) // remainder
+		})
+	}
+}
+
+func TestDivideErrors(t *testing.T) {
+	is := is.New(t)
+	_, _, err := Money{USD, 1, 0}.Divide(0)
+	is.True(err != nil) // division by zero
+	_, _, err = Money{USD, math.MinInt64, 0}.Divide(-1)
+	is.True(errors.Is(err, ErrOverflow)) // overflow
+}
+
+func TestAllocate(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		ratios   []int
+		expected []Money
+	}{
+		{Money{USD, 100, 0}, []int{1, 1}, []Money{{USD, 50, 0}, {USD, 50, 0}}},
+		{Money{USD, 100, 0}, []int{70, 30}, []Money{{USD, 70, 0}, {USD, 30, 0}}},
+		{Money{USD, 0, 5}, []int{1, 1}, []Money{{USD, 0, 3}, {USD, 0, 2}}},
+		{Money{USD, 0, 5}, []int{3, 7}, []Money{{USD, 0, 2}, {USD, 0, 3}}},
+		{Money{USD, 0, 10}, []int{1, 1, 1}, []Money{{USD, 0, 4}, {USD, 0, 3}, {USD, 0, 3}}},
+		{Money{USD, 0, -10}, []int{1, 1, 1}, []Money{{USD, 0, -4}, {USD, 0, -3}, {USD, 0, -3}}},
+		{Money{USD, 1, 0}, []int{1, 0, 2}, []Money{{USD, 0, 333333333}, {USD, 0, 0}, {USD, 0, 666666667}}},
+		{Money{USD, math.MaxInt64, maxNanos}, []int{1, 1}, []Money{{USD, 4611686018427387904, 0}, {USD, 4611686018427387903, 999999999}}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			parts, err := tc.money.Allocate(tc.ratios...)
+			is.NoErr(err)
+			is.Equal(parts, tc.expected)
+		})
+	}
+}
+
+func TestAllocateErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := Money{USD, 1, 0}.Allocate()
+	is.True(err != nil) // no ratios
+	_, err = Money{USD, 1, 0}.Allocate(1, -1)
+	is.True(err != nil) // negative ratio
+	_, err = Money{USD, 1, 0}.Allocate(0, 0)
+	is.True(err != nil) // zero ratios
+}
+
+// normalizedMoney builds a valid Money from arbitrary inputs for use in property tests.
+func normalizedMoney(units int64, nanos int32) Money {
+	nanos = nanos % (maxNanos + 1)
+	if (units < 0 && nanos > 0) || (units > 0 && nanos < 0) {
+		nanos = -nanos
+	}
+	return Money{USD, units, nanos}
+}
+
+func sumOf(t *testing.T, parts ...Money) Money {
+	sum := Money{USD, 0, 0}
+	for _, part := range parts {
+		var err error
+		sum, err = sum.Add(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sum
+}
+
+func TestDivideConservesMoney(t *testing.T) {
+	property := func(units int64, nanos int32, n int16) bool {
+		if n == 0 {
+			return true
+		}
+		m := normalizedMoney(units, nanos)
+		quotient, remainder, err := m.Divide(int(n))
+		if err != nil {
+			return false
+		}
+		product, err := quotient.Multiply(int(n), 0)
+		if err != nil {
+			return false
+		}
+		return sumOf(t, product, remainder).IsEqual(m)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAllocateConservesMoney(t *testing.T) {
+	property := func(units int64, nanos int32, ratios []uint16) bool {
+		m := normalizedMoney(units, nanos)
+		r := []int{1}
+		for _, ratio := range ratios {
+			r = append(r, int(ratio))
+		}
+		parts, err := m.Allocate(r...)
+		if err != nil || len(parts) != len(r) {
+			return false
+		}
+		for _, part := range parts {
+			// every part must have the same sign as the amount being allocated
+			if part.CurrencyCode != m.CurrencyCode || part.totalNanos().Sign()*m.totalNanos().Sign() < 0 {
+				return false
+			}
+		}
+		return sumOf(t, parts...).IsEqual(m)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		money Money
+		valid bool
+	}{
+		{Money{USD, 0, 0}, true},
+		{Money{USD, 1, 1}, true},
+		{Money{USD, -1, -1}, true},
+		{Money{USD, 0, -maxNanos}, true},
+		{Money{USD, math.MaxInt64, maxNanos}, true},
+		{Money{USD, 1, -1}, false},
+		{Money{USD, -1, 1}, false},
+		{Money{USD, 0, maxNanos + 1}, false},
+		{Money{USD, 0, -maxNanos - 1}, false},
+		{Money{"XYZ", 1, 0}, false},
+		{Money{}, false},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			err := tc.money.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("expected %#v to be valid but got %s", tc.money, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected %#v to be invalid", tc.money)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected Money
+	}{
+		{Money{USD, 1, 1}, Money{USD, 1, 1}},
+		{Money{USD, 1, 1500000000}, Money{USD, 2, 500000000}},
+		{Money{USD, 0, -2000000000}, Money{USD, -2, 0}},
+		{Money{USD, 1, -250000000}, Money{USD, 0, 750000000}},
+		{Money{USD, -1, 250000000}, Money{USD, 0, -750000000}},
+		{Money{USD, -3, 2147483647}, Money{USD, 0, -852516353}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.New(t)
+			actual, err := tc.money.Normalize()
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+			is.NoErr(actual.Validate())
+		})
+	}
+	_, err := Money{USD, math.MaxInt64, 2000000000}.Normalize()
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow but got %v", err)
+	}
+}
+
+func TestArithmeticValidatesInputs(t *testing.T) {
+	valid := Money{USD, 1, 0}
+	invalid := []Money{{USD, 1, -1}, {USD, 0, maxNanos + 1}, {"XYZ", 1, 0}}
+	for i, m := range invalid {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			_, err := m.Add(valid)
+			is.True(err != nil) // Add receiver
+			_, err = valid.Add(m)
+			is.True(err != nil) // Add argument
+			_, err = m.Subtract(valid)
+			is.True(err != nil) // Subtract receiver
+			_, err = valid.Subtract(m)
+			is.True(err != nil) // Subtract argument
+			_, err = m.Multiply(1, 0)
+			is.True(err != nil) // Multiply
+			_, _, err = m.Divide(1)
+			is.True(err != nil) // Divide
+			_, err = m.Allocate(1)
+			is.True(err != nil) // Allocate
+			_, err = m.Round(RoundHalfUp)
+			is.True(err != nil) // Round
+		})
+	}
+}
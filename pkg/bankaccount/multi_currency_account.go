@@ -0,0 +1,344 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MultiCurrencyAccount is an Account that holds a separate balance in each currency deposited into it, like a
+// wallet, instead of requiring every deposit to be in the same currency. Balances can be converted from one
+// currency to another within the account, and the account reports its consolidated balance in a reporting
+// currency.
+type MultiCurrencyAccount struct {
+	mu        sync.Mutex
+	balances  map[string]Money
+	reporting string
+	rounding  RoundingMode
+	rates     ExchangeRateProvider
+	pricing   Pricing
+	ledger    ledger
+	journal   *Journal
+	customer  LedgerAccount
+	seq       uint64
+	opening   []Money
+}
+
+type MultiCurrencyAccountOption func(*MultiCurrencyAccount)
+
+// WithReportingCurrency sets the currency the consolidated balance is reported in. By default, it is USD.
+func WithReportingCurrency(currencyCode string) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.reporting = currencyCode
+	}
+}
+
+// WithOpeningBalances sets the opening balances of the account. Balances in the same currency are added
+// together. NewMultiCurrencyAccount returns an error if any of them is not a valid Money.
+func WithOpeningBalances(balances ...Money) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.opening = append(a.opening, balances...)
+	}
+}
+
+// WithMultiCurrencyRoundingMode sets how fractional nanos are rounded when converting balances to another
+// currency. By default, RoundHalfUp is used.
+func WithMultiCurrencyRoundingMode(mode RoundingMode) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.rounding = mode
+	}
+}
+
+// WithMultiCurrencyRateProvider sets where the account looks up exchange rates. By default, CurrentRates is
+// used.
+func WithMultiCurrencyRateProvider(rates ExchangeRateProvider) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.rates = rates
+	}
+}
+
+// WithMultiCurrencyPricing sets the spread and fees charged when converting between currencies within the
+// account. By default, conversions are made at the mid-market rate for free.
+func WithMultiCurrencyPricing(p Pricing) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.pricing = p
+	}
+}
+
+// WithMultiCurrencyClock sets the clock used to timestamp transactions. By default, time.Now is used.
+func WithMultiCurrencyClock(now func() time.Time) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.ledger.now = now
+	}
+}
+
+// WithMultiCurrencyJournal posts every change to the balances to a double-entry journal, using the ledger
+// account of the customer with the given ID. Opening balances are posted as deposits when the account is
+// created.
+func WithMultiCurrencyJournal(j *Journal, customerID string) MultiCurrencyAccountOption {
+	return func(a *MultiCurrencyAccount) {
+		a.journal = j
+		a.customer = CustomerLedgerAccount(customerID)
+	}
+}
+
+// NewMultiCurrencyAccount opens an account with the given options. It returns an error if any opening balance
+// is invalid or the opening balances cannot be posted to the account's journal.
+func NewMultiCurrencyAccount(opts ...MultiCurrencyAccountOption) (*MultiCurrencyAccount, error) {
+	acct := &MultiCurrencyAccount{
+		balances:  map[string]Money{},
+		reporting: USD,
+		rates:     CurrentRates,
+		seq:       nextAccountSeq(),
+	}
+	for _, opt := range opts {
+		opt(acct)
+	}
+	for _, m := range acct.opening {
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid opening balance: %w", err)
+		}
+		if _, err := acct.credit(m); err != nil {
+			return nil, fmt.Errorf("opening balance of %s: %w", m, err)
+		}
+	}
+	acct.opening = nil
+	// post every currency in one entry, so that the journal never records only some of them
+	var postings []Posting
+	var opening []Transaction
+	for _, balance := range acct.Balances() {
+		postings = append(postings, depositPostings(acct.customer, balance, nil)...)
+		opening = append(opening, Transaction{Type: TransactionOpeningBalance, Amount: balance, Balance: balance})
+	}
+	if len(opening) > 0 {
+		if err := postTo(acct.journal, "opening balances", postings); err != nil {
+			return nil, fmt.Errorf("posting opening balances: %w", err)
+		}
+		acct.ledger.append(nil, opening...)
+	}
+	return acct, nil
+}
+
+// credit adds m to the balance in its currency. The caller must hold the lock.
+func (a *MultiCurrencyAccount) credit(m Money) (Money, error) {
+	balance, err := a.balanceLocked(m.CurrencyCode).Add(m)
+	if err != nil {
+		return Money{}, err
+	}
+	a.setBalanceLocked(balance)
+	return balance, nil
+}
+
+// debit subtracts m from the balance in its currency, unless that would overdraw it. The operation describes the
+// debit if it would. The caller must hold the lock.
+func (a *MultiCurrencyAccount) debit(operation string, m Money) (Money, error) {
+	balance, err := a.debitedBalance(operation, m)
+	if err != nil {
+		return Money{}, err
+	}
+	a.setBalanceLocked(balance)
+	return balance, nil
+}
+
+// debitedBalance returns what the balance in the currency of m would be after debiting m, without changing it.
+// The caller must hold the lock.
+func (a *MultiCurrencyAccount) debitedBalance(operation string, m Money) (Money, error) {
+	balance := a.balanceLocked(m.CurrencyCode)
+	overdrawn, err := balance.LessThan(m)
+	if err != nil {
+		return Money{}, err
+	}
+	if overdrawn {
+		return Money{}, &InsufficientFundsError{Operation: operation, Requested: m, Available: balance}
+	}
+	return balance.Subtract(m)
+}
+
+func (a *MultiCurrencyAccount) balanceLocked(currencyCode string) Money {
+	if balance, found := a.balances[currencyCode]; found {
+		return balance
+	}
+	return Money{CurrencyCode: currencyCode}
+}
+
+func (a *MultiCurrencyAccount) setBalanceLocked(balance Money) {
+	if balance.IsZero() {
+		delete(a.balances, balance.CurrencyCode)
+		return
+	}
+	a.balances[balance.CurrencyCode] = balance
+}
+
+// Balance returns the balance held in the reporting currency. Use ConsolidatedBalance for the value of all the
+// balances together.
+func (a *MultiCurrencyAccount) Balance() Money {
+	return a.BalanceIn(a.reporting)
+}
+
+// BalanceIn returns the balance held in the given currency, which is zero if none is held.
+func (a *MultiCurrencyAccount) BalanceIn(currencyCode string) Money {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.balanceLocked(currencyCode)
+}
+
+// Balances returns the nonzero balances held in each currency, sorted by currency code.
+func (a *MultiCurrencyAccount) Balances() []Money {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	balances := make([]Money, 0, len(a.balances))
+	for _, balance := range a.balances {
+		balances = append(balances, balance)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].CurrencyCode < balances[j].CurrencyCode })
+	return balances
+}
+
+// ConsolidatedBalance returns the value of all the balances together in the reporting currency.
+func (a *MultiCurrencyAccount) ConsolidatedBalance() (Money, error) {
+	return a.BalanceAsCurrency(a.reporting)
+}
+
+// BalanceAsCurrency returns the value of all the balances together in the given currency, converting each one at
+// the mid-market rate.
+func (a *MultiCurrencyAccount) BalanceAsCurrency(currencyCode string) (Money, error) {
+	if _, err := LookupCurrency(currencyCode); err != nil {
+		return Money{}, err
+	}
+	total := Money{CurrencyCode: currencyCode}
+	for _, balance := range a.Balances() {
+		rate, err := a.rates.Rate(context.Background(), balance.CurrencyCode, currencyCode)
+		if err != nil {
+			return Money{}, err
+		}
+		converted, err := rate.Convert(balance, a.rounding)
+		if err != nil {
+			return Money{}, err
+		}
+		if total, err = total.Add(converted); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Transactions returns the transactions the filter selects from the account's ledger, oldest first.
+func (a *MultiCurrencyAccount) Transactions(filter TransactionFilter) []Transaction {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ledger.query(filter)
+}
+
+// Deposit adds m to the balance held in its currency and records the transaction in the account's ledger.
+func (a *MultiCurrencyAccount) Deposit(m Money, opts ...TransactionOption) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	before := a.balanceLocked(m.CurrencyCode)
+	balance, err := a.credit(m)
+	if err != nil {
+		return err
+	}
+	if err := postTo(a.journal, fmt.Sprintf("deposit of %s", m), depositPostings(a.customer, m, nil)); err != nil {
+		a.setBalanceLocked(before)
+		return err
+	}
+	a.ledger.append(opts, Transaction{Type: TransactionDeposit, Amount: m, Balance: balance})
+	return nil
+}
+
+// Withdraw subtracts m from the balance held in its currency and records the transaction in the account's
+// ledger. Balances in other currencies are not converted to cover it.
+func (a *MultiCurrencyAccount) Withdraw(m Money, opts ...TransactionOption) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.debitAndRecord(Transaction{Type: TransactionWithdrawal, Amount: m}, withdrawalPostings(a.customer, m, nil), opts)
+}
+
+// ChargeFee subtracts a fee charged by the bank from the balance held in its currency and records the
+// transaction in the account's ledger. The fee must be positive.
+func (a *MultiCurrencyAccount) ChargeFee(fee Money, opts ...TransactionOption) error {
+	if err := checkFee(fee); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.debitAndRecord(Transaction{Type: TransactionFee, Amount: fee}, feePostings(a.customer, fee), opts)
+}
+
+// debitAndRecord subtracts the amount of the transaction from the balance in its currency, unless that would
+// overdraw it, posts it to the journal and records it in the ledger. The caller must hold the lock.
+func (a *MultiCurrencyAccount) debitAndRecord(t Transaction, postings []Posting, opts []TransactionOption) error {
+	before := a.balanceLocked(t.Amount.CurrencyCode)
+	balance, err := a.debit(t.Type.String(), t.Amount)
+	if err != nil {
+		return err
+	}
+	if err := postTo(a.journal, fmt.Sprintf("%s of %s", t.Type, t.Amount), postings); err != nil {
+		a.setBalanceLocked(before)
+		return err
+	}
+	t.Balance = balance
+	a.ledger.append(opts, t)
+	return nil
+}
+
+// Convert moves an amount from the balance held in one currency to the balance held in another, using the
+// account's rate provider and pricing. The amount must be in the currency converted from. The quote returned
+// shows what was credited to the other balance. The conversion is recorded in the account's ledger as a
+// withdrawal from one balance and a deposit to the other.
+func (a *MultiCurrencyAccount) Convert(from string, to string, amount Money, opts ...TransactionOption) (ConversionQuote, error) {
+	if err := amount.Validate(); err != nil {
+		return ConversionQuote{}, err
+	}
+	if amount.CurrencyCode != from {
+		return ConversionQuote{}, &CurrencyMismatchError{Operation: "converting it from " + from, Expected: from, Actual: amount.CurrencyCode}
+	}
+	rate, err := a.rates.Rate(context.Background(), from, to)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	quote, err := a.pricing.Quote(rate, amount, a.rounding)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fromBefore, toBefore := a.balanceLocked(from), a.balanceLocked(to)
+	rollback := func() {
+		a.setBalanceLocked(fromBefore)
+		a.setBalanceLocked(toBefore)
+	}
+	debited, err := a.debit("conversion", amount)
+	if err != nil {
+		return ConversionQuote{}, err
+	}
+	credited, err := a.credit(quote.Net)
+	if err != nil {
+		rollback()
+		return ConversionQuote{}, err
+	}
+	memo := fmt.Sprintf("conversion of %s to %s", amount, to)
+	if err := postTo(a.journal, memo, conversionPostings(a.customer, quote)); err != nil {
+		rollback()
+		return ConversionQuote{}, err
+	}
+	conversion := &Conversion{Original: amount, Rate: quote.Rate, Converted: quote.Net}
+	a.ledger.append(opts,
+		Transaction{Type: TransactionWithdrawal, Amount: amount, Balance: debited, Conversion: conversion},
+		Transaction{Type: TransactionDeposit, Amount: quote.Net, Balance: credited, Conversion: conversion},
+	)
+	return quote, nil
+}
+
+func (a *MultiCurrencyAccount) RemittanceAddress() string {
+	return "742 Evergreen Terrace\nSpringfield, OR"
+}
@@ -0,0 +1,109 @@
+package bankaccount
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// mustNewMultiCurrencyAccount returns a new MultiCurrencyAccount, failing the test if it cannot be opened.
+func mustNewMultiCurrencyAccount(t *testing.T, opts ...MultiCurrencyAccountOption) *MultiCurrencyAccount {
+	t.Helper()
+	acct, err := NewMultiCurrencyAccount(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acct
+}
+
+func TestMultiCurrencyAccount(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{USD, 10, 0}, Money{USD, 5, 0}))
+	is.Equal(acct.Balance(), Money{USD, 15, 0})
+
+	is.NoErr(acct.Deposit(Money{CAD, 100, 0}))
+	is.NoErr(acct.Deposit(Money{EUR, 50, 0}))
+	is.Equal(acct.Balances(), []Money{{CAD, 100, 0}, {EUR, 50, 0}, {USD, 15, 0}})
+	is.Equal(acct.Balance(), Money{USD, 15, 0}) // only the balance held in the reporting currency
+	is.Equal(acct.BalanceIn("JPY"), Money{CurrencyCode: "JPY"})
+
+	consolidated, err := acct.ConsolidatedBalance()
+	is.NoErr(err)
+	is.Equal(consolidated, Money{USD, 149, 0}) // 80 + 54 + 15
+
+	is.NoErr(acct.Withdraw(Money{CAD, 100, 0}))
+	is.Equal(acct.Balances(), []Money{{EUR, 50, 0}, {USD, 15, 0}}) // empty balances are dropped
+	is.True(acct.Withdraw(Money{CAD, 1, 0}) != nil)                // nothing held in CAD
+	is.True(acct.Withdraw(Money{USD, 16, 0}) != nil)               // other currencies are not used to cover it
+	is.True(acct.Deposit(Money{"XYZ", 1, 0}) != nil)
+}
+
+func TestInvalidOpeningBalances(t *testing.T) {
+	is := is.New(t)
+	j := NewJournal()
+	for _, opts := range [][]MultiCurrencyAccountOption{
+		{WithOpeningBalances(Money{USD, 10, 0}, Money{CAD, 1, -1})},
+		{WithOpeningBalances(Money{USD, 10, 0}, Money{CAD, 1, -1}), WithMultiCurrencyJournal(j, "bob")},
+		{WithOpeningBalances(Money{"XYZ", 1, 0}), WithMultiCurrencyJournal(j, "bob")},
+	} {
+		acct, err := NewMultiCurrencyAccount(opts...)
+		is.True(errors.Is(err, ErrInvalidMoney)) // rejected with or without a journal
+		is.True(acct == nil)
+	}
+	_, err := NewMultiCurrencyAccount(WithOpeningBalances(Money{USD, 9223372036854775807, 0}, Money{USD, 1, 0}))
+	is.True(errors.Is(err, ErrOverflow))
+	is.Equal(len(j.Entries()), 0) // nothing was posted
+
+	acct := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{USD, 10, 0}, Money{CAD, 5, 0}), WithMultiCurrencyJournal(j, "bob"))
+	is.Equal(acct.Balances(), []Money{{CAD, 5, 0}, {USD, 10, 0}})
+	is.Equal(len(j.Entries()), 1) // every currency is posted in one entry
+}
+
+func TestMultiCurrencyAccountReportingCurrency(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewMultiCurrencyAccount(t,
+		WithReportingCurrency(CAD),
+		WithOpeningBalances(Money{USD, 80, 0}, Money{CAD, 20, 0}),
+	)
+	is.Equal(acct.Balance(), Money{CAD, 20, 0})
+	consolidated, err := acct.ConsolidatedBalance()
+	is.NoErr(err)
+	is.Equal(consolidated, Money{CAD, 120, 0})
+	inEUR, err := acct.BalanceAsCurrency(EUR)
+	is.NoErr(err)
+	is.Equal(inEUR.CurrencyCode, EUR)
+
+	empty := mustNewMultiCurrencyAccount(t, WithReportingCurrency("JPY"))
+	consolidated, err = empty.ConsolidatedBalance()
+	is.NoErr(err)
+	is.Equal(consolidated, Money{CurrencyCode: "JPY"})
+
+	_, err = mustNewMultiCurrencyAccount(t, WithReportingCurrency("XYZ")).ConsolidatedBalance()
+	is.True(err != nil)
+	_, err = mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{"JPY", 1, 0})).ConsolidatedBalance()
+	is.True(errors.Is(err, ErrRateNotFound)) // no rate from JPY
+}
+
+func TestMultiCurrencyAccountConvert(t *testing.T) {
+	is := is.New(t)
+	acct := mustNewMultiCurrencyAccount(t,
+		WithOpeningBalances(Money{CAD, 100, 0}),
+		WithMultiCurrencyPricing(Pricing{SpreadBasisPoints: 100, Fees: FeeSchedule{USD: {Fixed: Money{USD, 1, 0}}}}),
+	)
+	quote, err := acct.Convert(CAD, USD, Money{CAD, 50, 0})
+	is.NoErr(err)
+	is.Equal(quote.Gross, Money{USD, 39, 600000000})
+	is.Equal(quote.Net, Money{USD, 38, 600000000})
+	is.Equal(acct.Balances(), []Money{{CAD, 50, 0}, {USD, 38, 600000000}})
+
+	_, err = acct.Convert(CAD, USD, Money{CAD, 51, 0})
+	is.True(errors.Is(err, ErrInsufficientFunds)) // would overdraw the CAD balance
+	_, err = acct.Convert(CAD, USD, Money{USD, 1, 0})
+	is.True(errors.Is(err, ErrCurrencyMismatch)) // the amount is not in CAD
+	_, err = acct.Convert(CAD, "JPY", Money{CAD, 1, 0})
+	is.True(err != nil) // no rate
+	_, err = acct.Convert(CAD, USD, Money{CAD, 1, 0})
+	is.True(err != nil)                                                    // the fee exceeds the converted amount
+	is.Equal(acct.Balances(), []Money{{CAD, 50, 0}, {USD, 38, 600000000}}) // unchanged by failed conversions
+}
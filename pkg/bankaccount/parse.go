@@ -0,0 +1,139 @@
+package bankaccount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// currenciesBySymbol indexes the currencies that have a symbol distinct from their code, e.g., "$" and "€".
+var currenciesBySymbol = func() map[string]Currency {
+	bySymbol := map[string]Currency{}
+	for _, c := range iso4217 {
+		if c.Symbol != c.Code {
+			bySymbol[c.Symbol] = c
+		}
+	}
+	return bySymbol
+}()
+
+// ParseMoney parses a human-readable amount such as "USD 1,234.05", "$-0.75" or "1.05 EUR". The currency may
+// be given as either an ISO 4217 code or a symbol, and either before or after the amount. The amount may
+// have a leading sign, comma thousand separators, and up to nine fractional digits. Text that cannot be parsed
+// is reported as an InvalidMoneyError.
+func ParseMoney(s string) (Money, error) {
+	rest, negative := trimSign(strings.TrimSpace(s))
+
+	// anything before the amount is a currency prefix
+	i := strings.IndexFunc(rest, func(r rune) bool {
+		return isDigit(r) || r == '.' || r == '-' || r == '+'
+	})
+	if i < 0 {
+		return Money{}, invalidInput(s, errors.New("missing amount"))
+	}
+	prefix := strings.TrimSpace(rest[:i])
+	rest = rest[i:]
+	if prefix != "" {
+		var signed bool
+		rest, signed = trimSign(rest)
+		if signed && negative {
+			return Money{}, invalidInput(s, errors.New("more than one sign"))
+		}
+		negative = negative || signed
+	}
+
+	// anything after the amount is a currency suffix
+	j := strings.LastIndexFunc(rest, isDigit)
+	if j < 0 {
+		return Money{}, invalidInput(s, errors.New("missing amount"))
+	}
+	amount := rest[:j+1]
+	suffix := strings.TrimSpace(rest[j+1:])
+
+	var currency string
+	switch {
+	case prefix != "" && suffix != "":
+		return Money{}, invalidInput(s, errors.New("currency given both before and after the amount"))
+	case prefix != "":
+		currency = prefix
+	case suffix != "":
+		currency = suffix
+	default:
+		return Money{}, invalidInput(s, errors.New("missing currency"))
+	}
+	c, err := lookupCurrencyOrSymbol(currency)
+	if err != nil {
+		return Money{}, invalidInput(s, err)
+	}
+
+	nanos, err := parseDecimal(amount)
+	if err != nil {
+		return Money{}, invalidInput(s, err)
+	}
+	if negative {
+		nanos.Neg(nanos)
+	}
+	m, err := fromNanos(c.Code, nanos)
+	if err != nil {
+		return Money{}, invalidInput(s, err)
+	}
+	return m, nil
+}
+
+func lookupCurrencyOrSymbol(s string) (Currency, error) {
+	if c, found := currenciesBySymbol[s]; found {
+		return c, nil
+	}
+	return LookupCurrency(s)
+}
+
+// trimSign removes a leading "-" or "+" from s, reporting whether the sign was negative.
+func trimSign(s string) (string, bool) {
+	switch {
+	case strings.HasPrefix(s, "-"):
+		return strings.TrimSpace(s[1:]), true
+	case strings.HasPrefix(s, "+"):
+		return strings.TrimSpace(s[1:]), false
+	}
+	return s, false
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// parseDecimal parses an unsigned decimal number such as "1,234.05" into a number of nanos. Thousand
+// separators must be placed every three digits, and at most nine fractional digits are allowed.
+func parseDecimal(s string) (*big.Int, error) {
+	whole, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+		if fraction == "" {
+			return nil, fmt.Errorf("missing digits after the decimal point")
+		}
+	}
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("missing amount")
+	}
+	if strings.Contains(whole, ",") {
+		groups := strings.Split(whole, ",")
+		for i, group := range groups {
+			if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+				return nil, fmt.Errorf("misplaced thousand separator in %q", s)
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	if len(fraction) > 9 {
+		return nil, fmt.Errorf("more than nine fractional digits in %q", s)
+	}
+	for _, r := range whole + fraction {
+		if !isDigit(r) {
+			return nil, fmt.Errorf("unexpected character %q in %q", r, s)
+		}
+	}
+	digits := whole + fraction + strings.Repeat("0", 9-len(fraction))
+	nanos, _ := new(big.Int).SetString(digits, 10)
+	return nanos, nil
+}
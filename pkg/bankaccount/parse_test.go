@@ -0,0 +1,80 @@
+package bankaccount
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Money
+	}{
+		{"USD 1,234.05", Money{USD, 1234, 50000000}},
+		{"$-0.75", Money{USD, 0, -750000000}},
+		{"-$0.75", Money{USD, 0, -750000000}},
+		{"1.05 EUR", Money{EUR, 1, 50000000}},
+		{"-1.05 EUR", Money{EUR, -1, -50000000}},
+		{"+1.05 EUR", Money{EUR, 1, 50000000}},
+		{"€1.05", Money{EUR, 1, 50000000}},
+		{"USD -12", Money{USD, -12, 0}},
+		{"USD1", Money{USD, 1, 0}},
+		{"  100.00 CAD  ", Money{CAD, 100, 0}},
+		{"CA$ 100", Money{CAD, 100, 0}},
+		{"CN¥16", Money{CNY, 16, 0}},
+		{"¥1,000", Money{"JPY", 1000, 0}},
+		{"1,234,567.123456789 KWD", Money{"KWD", 1234567, 123456789}},
+		{"USD .5", Money{USD, 0, 500000000}},
+		{"USD 0.000000001", Money{USD, 0, 1}},
+		{"USD 9,223,372,036,854,775,807.999999999", Money{USD, 9223372036854775807, 999999999}},
+		{"USD -9223372036854775808", Money{USD, -9223372036854775808, 0}},
+		{"5 F CFA", Money{"XOF", 5, 0}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			actual, err := ParseMoney(tc.input)
+			is.NoErr(err)
+			is.Equal(actual, tc.expected)
+		})
+	}
+}
+
+func TestParseMoneyErrors(t *testing.T) {
+	testCases := []string{
+		"",
+		"USD",
+		"1.05",
+		"1.05 XYZ",
+		"usd 1.05",
+		"USD 1.05 EUR",
+		"USD 1.",
+		"USD 1.0000000001",
+		"USD 1,23.05",
+		"USD 1234,567",
+		"USD ,123",
+		"USD 1.234,05",
+		"USD 1 000",
+		"USD 1.2.3",
+		"--1.00 USD",
+		"-$-1.00",
+		"USD 1e6",
+	}
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseMoney(input)
+			if err == nil {
+				t.Errorf("expected an error parsing %q", input)
+			}
+		})
+	}
+}
+
+func TestParseMoneyOverflow(t *testing.T) {
+	_, err := ParseMoney("USD 9223372036854775808")
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow but got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package bankaccount
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FeedFormat parses the body of an exchange rate feed into the rates it quotes.
+type FeedFormat func(r io.Reader) ([]Rate, error)
+
+// ParseECBFeed parses the European Central Bank's euro foreign exchange reference rates XML, e.g.,
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml. Each rate is quoted from EUR and takes effect
+// at midnight UTC on the day it was published. Both the daily feed and the historical feeds, which list more
+// than one day, are supported. Rates for currencies that are not in the registry are skipped.
+func ParseECBFeed(r io.Reader) ([]Rate, error) {
+	var doc struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube>Cube"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing ECB feed: %w", err)
+	}
+	if len(doc.Days) == 0 {
+		return nil, fmt.Errorf("parsing ECB feed: no rates found")
+	}
+	rates := []Rate{}
+	for _, day := range doc.Days {
+		effectiveAt, err := time.Parse("2006-01-02", day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ECB feed: invalid date %q", day.Time)
+		}
+		for _, quote := range day.Rates {
+			if _, err := LookupCurrency(quote.Currency); err != nil {
+				continue
+			}
+			rate, err := NewRate(EUR, quote.Currency, quote.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("parsing ECB feed for %s: %w", day.Time, err)
+			}
+			rate.EffectiveAt = effectiveAt
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// ParseOpenExchangeRatesFeed parses the JSON format used by OpenExchangeRates, e.g.,
+//
+//	{"timestamp": 1704456000, "base": "USD", "rates": {"CAD": 1.336501, "EUR": 0.915632}}
+//
+// Each rate is quoted from the base currency and takes effect at the timestamp. Rates for currencies that are not
+// in the registry, such as cryptocurrencies, are skipped.
+func ParseOpenExchangeRatesFeed(r io.Reader) ([]Rate, error) {
+	var doc struct {
+		Timestamp int64                  `json:"timestamp"`
+		Base      string                 `json:"base"`
+		Rates     map[string]json.Number `json:"rates"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenExchangeRates feed: %w", err)
+	}
+	if _, err := LookupCurrency(doc.Base); err != nil {
+		return nil, fmt.Errorf("parsing OpenExchangeRates feed: base currency: %w", err)
+	}
+	if len(doc.Rates) == 0 {
+		return nil, fmt.Errorf("parsing OpenExchangeRates feed: no rates found")
+	}
+	effectiveAt := time.Unix(doc.Timestamp, 0).UTC()
+	rates := make([]Rate, 0, len(doc.Rates))
+	for currency, quote := range doc.Rates {
+		if _, err := LookupCurrency(currency); err != nil {
+			continue
+		}
+		rate, err := NewRate(doc.Base, currency, quote.String())
+		if err != nil {
+			return nil, fmt.Errorf("parsing OpenExchangeRates feed: %w", err)
+		}
+		rate.EffectiveAt = effectiveAt
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].To < rates[j].To })
+	return rates, nil
+}
+
+// HTTPRateProvider is an ExchangeRateProvider that fetches its rates from an HTTP feed. The fetched table is
+// cached for a time to live; once it expires, the next lookup fetches the feed again. If a fetch fails, the last
+// table that was fetched successfully keeps being used, and the feed is retried after the retry interval.
+// Concurrent lookups share a single fetch of the feed, and lookups that do not need to fetch it are never held up
+// by one. Inverse and cross rates are derived as by ExchangeRates. It is safe for concurrent use.
+type HTTPRateProvider struct {
+	url           string
+	format        FeedFormat
+	client        *http.Client
+	ttl           time.Duration
+	retryInterval time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	rates     ExchangeRates
+	loaded    bool
+	nextFetch time.Time
+	lastErr   error
+	inFlight  *feedFetch
+}
+
+// feedFetch is a fetch of the feed in progress, which concurrent lookups wait for instead of fetching it again.
+type feedFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// DefaultFeedTimeout is how long an HTTPRateProvider waits for the feed by default before giving up on a fetch.
+const DefaultFeedTimeout = 30 * time.Second
+
+type HTTPRateProviderOption func(*HTTPRateProvider)
+
+// WithHTTPClient sets the client used to fetch the feed. By default, a client with a timeout of
+// DefaultFeedTimeout is used. The client should have a timeout, since a fetch is shared by every lookup waiting
+// for it and is not cancelled when one of them gives up.
+func WithHTTPClient(client *http.Client) HTTPRateProviderOption {
+	return func(p *HTTPRateProvider) {
+		p.client = client
+	}
+}
+
+// WithTTL sets how long a fetched table is used before the feed is fetched again. By default, it is one hour.
+func WithTTL(ttl time.Duration) HTTPRateProviderOption {
+	return func(p *HTTPRateProvider) {
+		p.ttl = ttl
+	}
+}
+
+// WithRetryInterval sets how long to wait before fetching the feed again after a failed fetch, while the last
+// good table is used. By default, it is one minute.
+func WithRetryInterval(interval time.Duration) HTTPRateProviderOption {
+	return func(p *HTTPRateProvider) {
+		p.retryInterval = interval
+	}
+}
+
+// NewHTTPRateProvider returns a provider for the feed at the given URL, which is parsed using the given format,
+// e.g., ParseECBFeed. The feed is not fetched until the first lookup.
+func NewHTTPRateProvider(url string, format FeedFormat, opts ...HTTPRateProviderOption) *HTTPRateProvider {
+	p := &HTTPRateProvider{
+		url:           url,
+		format:        format,
+		client:        &http.Client{Timeout: DefaultFeedTimeout},
+		ttl:           time.Hour,
+		retryInterval: time.Minute,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Rate returns the rate for converting from one currency to another, fetching the feed first if the cached
+// table has expired. An error is returned only if no table has ever been fetched successfully, if the table has
+// no rate for the currencies, or if ctx is done while waiting for the feed.
+func (p *HTTPRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if err := p.refresh(ctx, false); err != nil && ctx.Err() != nil {
+		return Rate{}, ctx.Err()
+	}
+	p.mu.Lock()
+	rates, loaded, lastErr := p.rates, p.loaded, p.lastErr
+	p.mu.Unlock()
+
+	if !loaded {
+		return Rate{}, lastErr
+	}
+	return rates.Rate(ctx, from, to)
+}
+
+// Refresh fetches the feed immediately, regardless of whether the cached table has expired. If the fetch fails,
+// the error is returned and the last good table keeps being used. If ctx is done first, ctx.Err() is returned, but
+// the fetch carries on for any other lookups waiting for it.
+func (p *HTTPRateProvider) Refresh(ctx context.Context) error {
+	return p.refresh(ctx, true)
+}
+
+// LastError returns the error from the most recent fetch, or nil if it succeeded.
+func (p *HTTPRateProvider) LastError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastErr
+}
+
+// refresh fetches the feed, unless it is not due to be fetched yet and force is false, and returns the error from
+// the fetch. A fetch that is already in progress is waited for instead of starting another one.
+func (p *HTTPRateProvider) refresh(ctx context.Context, force bool) error {
+	p.mu.Lock()
+	f := p.inFlight
+	if f == nil {
+		if !force && p.now().Before(p.nextFetch) {
+			p.mu.Unlock()
+			return nil
+		}
+		f = &feedFetch{done: make(chan struct{})}
+		p.inFlight = f
+		go p.fetchInBackground(f)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetchInBackground fetches the feed without holding the lock, so lookups that do not need to wait for it are not
+// blocked, and then stores the result. The fetch is not tied to the context of any one lookup, so one that gives
+// up neither cancels it for the others nor is recorded as a failure of the feed.
+func (p *HTTPRateProvider) fetchInBackground(f *feedFetch) {
+	rates, err := p.fetch(context.Background())
+
+	p.mu.Lock()
+	if err != nil {
+		p.lastErr = fmt.Errorf("fetching exchange rates from %s: %w", p.url, err)
+		p.nextFetch = p.now().Add(p.retryInterval)
+	} else {
+		p.rates = rates
+		p.loaded = true
+		p.lastErr = nil
+		p.nextFetch = p.now().Add(p.ttl)
+	}
+	f.err = p.lastErr
+	p.inFlight = nil
+	p.mu.Unlock()
+	close(f.done)
+}
+
+func (p *HTTPRateProvider) fetch(ctx context.Context) (ExchangeRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ExchangeRates{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	rates, err := p.format(resp.Body)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+	// a historical feed quotes each pair more than once, so keep the latest
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].EffectiveAt.Before(rates[j].EffectiveAt) })
+	return NewExchangeRates(rates...), nil
+}
@@ -0,0 +1,208 @@
+package bankaccount
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dumpsterfireproject/godog-examples/pkg/bankaccount/ratefeedtest"
+	"github.com/matryer/is"
+)
+
+const ecbDaily = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2024-01-05'>
+			<Cube currency='USD' rate='1.0921'/>
+			<Cube currency='JPY' rate='158.16'/>
+			<Cube currency='HRK' rate='7.5345'/>
+		</Cube>
+		<Cube time='2024-01-04'>
+			<Cube currency='USD' rate='1.0953'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParseECBFeed(t *testing.T) {
+	is := is.New(t)
+	rates, err := ParseECBFeed(strings.NewReader(ecbDaily))
+	is.NoErr(err)
+	is.Equal(rates, []Rate{
+		{From: EUR, To: USD, Units: 1, Nanos: 92100000, EffectiveAt: date("2024-01-05")},
+		{From: EUR, To: "JPY", Units: 158, Nanos: 160000000, EffectiveAt: date("2024-01-05")},
+		{From: EUR, To: USD, Units: 1, Nanos: 95300000, EffectiveAt: date("2024-01-04")},
+	}) // HRK is no longer in the registry, so it is skipped
+}
+
+func TestParseOpenExchangeRatesFeed(t *testing.T) {
+	is := is.New(t)
+	rates, err := ParseOpenExchangeRatesFeed(strings.NewReader(`{
+		"disclaimer": "Usage subject to terms",
+		"timestamp": 1704456000,
+		"base": "USD",
+		"rates": {"CAD": 1.336501, "EUR": 0.915632, "BTC": 0.000022712}
+	}`))
+	is.NoErr(err)
+	published := time.Unix(1704456000, 0).UTC()
+	is.Equal(rates, []Rate{
+		{From: USD, To: CAD, Units: 1, Nanos: 336501000, EffectiveAt: published},
+		{From: USD, To: EUR, Units: 0, Nanos: 915632000, EffectiveAt: published},
+	}) // BTC is not a currency in the registry, so it is skipped
+}
+
+func TestParseFeedErrors(t *testing.T) {
+	ecbCases := []string{
+		"",
+		"<gesmes:Envelope/>",
+		"<Envelope><Cube><Cube time='2024-01-05'><Cube currency='USD' rate='-1'/></Cube></Cube></Envelope>",
+		"<Envelope><Cube><Cube time='Jan 5'><Cube currency='USD' rate='1.09'/></Cube></Cube></Envelope>",
+	}
+	for _, input := range ecbCases {
+		if _, err := ParseECBFeed(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error parsing ECB feed %q", input)
+		}
+	}
+	oxrCases := []string{
+		"",
+		`{"base": "USD", "rates": {}}`,
+		`{"base": "XYZ", "rates": {"CAD": 1.33}}`,
+		`{"base": "USD", "rates": {"CAD": 0}}`,
+		`{"base": "USD", "rates": {"CAD": "abc"}}`,
+	}
+	for _, input := range oxrCases {
+		if _, err := ParseOpenExchangeRatesFeed(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error parsing OpenExchangeRates feed %q", input)
+		}
+	}
+}
+
+func TestHTTPRateProvider(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server := ratefeedtest.NewServer()
+	defer server.Close()
+	server.ServeECB("2024-01-05", map[string]string{USD: "1.25", CAD: "1.5"})
+
+	now := date("2024-01-05")
+	provider := NewHTTPRateProvider(server.URL, ParseECBFeed, WithTTL(time.Hour), WithRetryInterval(time.Minute))
+	provider.now = func() time.Time { return now }
+
+	rate, err := provider.Rate(ctx, CAD, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "CAD/USD 0.833333333") // cross rate derived through EUR
+	is.Equal(server.Requests(), 1)
+
+	// the table is cached until the time to live expires
+	server.ServeECB("2024-01-06", map[string]string{USD: "1.2", CAD: "1.5"})
+	now = now.Add(59 * time.Minute)
+	rate, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.25")
+	is.Equal(server.Requests(), 1)
+
+	now = now.Add(time.Minute)
+	rate, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.2")
+	is.Equal(server.Requests(), 2)
+
+	// the last good table is used while the feed is failing, and the feed is retried after the retry interval
+	server.Fail(http.StatusInternalServerError)
+	now = now.Add(time.Hour)
+	rate, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.2")
+	is.True(provider.LastError() != nil)
+	is.Equal(server.Requests(), 3)
+	_, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.Equal(server.Requests(), 3)
+
+	server.ServeBody("text/xml", "<not a feed")
+	now = now.Add(time.Minute)
+	_, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.True(provider.LastError() != nil)
+	is.Equal(server.Requests(), 4)
+
+	server.ServeECB("2024-01-06", map[string]string{USD: "1.1"})
+	is.NoErr(provider.Refresh(ctx))
+	rate, err = provider.Rate(ctx, EUR, USD)
+	is.NoErr(err)
+	is.Equal(rate.String(), "EUR/USD 1.1")
+	is.NoErr(provider.LastError())
+	_, err = provider.Rate(ctx, EUR, CAD)
+	is.True(err != nil) // CAD is no longer quoted
+}
+
+func TestHTTPRateProviderWithHungFeed(t *testing.T) {
+	is := is.New(t)
+	release := make(chan struct{})
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		<-release // never responds until the test is over
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewHTTPRateProvider(server.URL, ParseECBFeed, WithHTTPClient(&http.Client{Timeout: 200 * time.Millisecond}))
+
+	// a lookup that gives up is not recorded as a failure of the feed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := provider.Rate(ctx, EUR, USD)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.NoErr(provider.LastError()) // not held up by the fetch, which is still in progress
+
+	// concurrent lookups share the fetch in progress, until the client times out
+	wg := sync.WaitGroup{}
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = provider.Rate(context.Background(), EUR, USD)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		is.True(err != nil) // nothing has been fetched yet
+	}
+	is.True(provider.LastError() != nil)
+	is.Equal(atomic.LoadInt64(&requests), int64(1))
+}
+
+func TestHTTPRateProviderWithoutTable(t *testing.T) {
+	is := is.New(t)
+	server := ratefeedtest.NewServer()
+	defer server.Close()
+
+	provider := NewHTTPRateProvider(server.URL, ParseOpenExchangeRatesFeed)
+	_, err := provider.Rate(context.Background(), USD, CAD)
+	is.True(err != nil) // nothing has been fetched yet
+	is.True(strings.Contains(err.Error(), "503"))
+}
+
+func TestBalanceAsCurrencyWithHTTPRateProvider(t *testing.T) {
+	is := is.New(t)
+	server := ratefeedtest.NewServer()
+	defer server.Close()
+	server.ServeOpenExchangeRates(USD, date("2024-01-05"), map[string]string{CAD: "1.25", EUR: "0.8"})
+
+	provider := NewHTTPRateProvider(server.URL, ParseOpenExchangeRatesFeed)
+	account := mustNewSavingsAccount(t, WithBalance(Money{CAD, 100, 0}), WithRateProvider(provider))
+	balance, err := account.BalanceAsCurrency(EUR)
+	is.NoErr(err)
+	is.Equal(balance, Money{EUR, 64, 0})
+}
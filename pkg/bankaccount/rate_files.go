@@ -0,0 +1,135 @@
+package bankaccount
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReadRatesCSV reads rates from CSV with a header row naming the columns from, to, rate and effective_at, e.g.,
+//
+//	from,to,rate,effective_at
+//	CAD,USD,0.8,2024-01-05
+//
+// The effective_at column is optional and may be either a date or an RFC 3339 timestamp. An empty value means the
+// rate has always been in effect.
+func ReadRatesCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading rates header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"from", "to", "rate"} {
+		if _, found := columns[name]; !found {
+			return nil, fmt.Errorf("rates header is missing the %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, found := columns[name]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rates := []Rate{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading rates: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		rate, err := newRateAt(field(record, "from"), field(record, "to"), field(record, "rate"), field(record, "effective_at"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+}
+
+type rateJSON struct {
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Rate        json.Number `json:"rate"`
+	EffectiveAt string      `json:"effective_at,omitempty"`
+}
+
+// ReadRatesJSON reads rates from a JSON array of objects with the same fields as ReadRatesCSV, e.g.,
+//
+//	[{"from": "CAD", "to": "USD", "rate": 0.8, "effective_at": "2024-01-05"}]
+//
+// The rate may be given as either a number or a string.
+func ReadRatesJSON(r io.Reader) ([]Rate, error) {
+	var records []rateJSON
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("reading rates: %w", err)
+	}
+	rates := make([]Rate, 0, len(records))
+	for i, record := range records {
+		rate, err := newRateAt(record.From, record.To, record.Rate.String(), record.EffectiveAt)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func newRateAt(from string, to string, rate string, effectiveAt string) (Rate, error) {
+	r, err := NewRate(from, to, rate)
+	if err != nil {
+		return Rate{}, err
+	}
+	if effectiveAt != "" {
+		r.EffectiveAt, err = time.Parse(time.RFC3339, effectiveAt)
+		if err != nil {
+			r.EffectiveAt, err = time.Parse("2006-01-02", effectiveAt)
+		}
+		if err != nil {
+			return Rate{}, fmt.Errorf("invalid effective_at %q for %s/%s", effectiveAt, from, to)
+		}
+	}
+	return r, nil
+}
+
+// LoadRatesFile reads the rates in a .csv or .json file and returns the rates currently in effect. When a file
+// holds more than one rate for the same pair of currencies, the most recent one that is already in effect is
+// used.
+func LoadRatesFile(path string) (ExchangeRates, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+	defer f.Close()
+
+	var rates []Rate
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		rates, err = ReadRatesCSV(f)
+	case ".json":
+		rates, err = ReadRatesJSON(f)
+	default:
+		return ExchangeRates{}, fmt.Errorf("unsupported rates file type %q", ext)
+	}
+	if err != nil {
+		return ExchangeRates{}, fmt.Errorf("%s: %w", path, err)
+	}
+	history, err := NewHistoricalRates(rates...)
+	if err != nil {
+		return ExchangeRates{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return history.AsOf(time.Now()), nil
+}
@@ -0,0 +1,86 @@
+package bankaccount
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestReadRatesCSV(t *testing.T) {
+	is := is.New(t)
+	rates, err := ReadRatesCSV(strings.NewReader("to, from, rate, effective_at\nUSD, CAD, \"1,080.25\", 2024-01-05\nUSD,EUR,1.08,2024-01-05T12:00:00Z\nUSD,CNY,0.16,\n"))
+	is.NoErr(err)
+	is.Equal(rates, []Rate{
+		{From: CAD, To: USD, Units: 1080, Nanos: 250000000, EffectiveAt: date("2024-01-05")},
+		{From: EUR, To: USD, Units: 1, Nanos: 80000000, EffectiveAt: time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)},
+		{From: CNY, To: USD, Units: 0, Nanos: 160000000},
+	})
+}
+
+func TestReadRatesJSON(t *testing.T) {
+	is := is.New(t)
+	rates, err := ReadRatesJSON(strings.NewReader(`[{"from":"CAD","to":"USD","rate":0.8,"effective_at":"2024-01-05"},{"from":"CNY","to":"USD","rate":"0.16"}]`))
+	is.NoErr(err)
+	is.Equal(rates, []Rate{
+		{From: CAD, To: USD, Units: 0, Nanos: 800000000, EffectiveAt: date("2024-01-05")},
+		{From: CNY, To: USD, Units: 0, Nanos: 160000000},
+	})
+}
+
+func TestReadRatesErrors(t *testing.T) {
+	csvCases := []string{
+		"",
+		"from,to\nCAD,USD\n",
+		"from,to,rate\nCAD,XYZ,0.8\n",
+		"from,to,rate\nCAD,USD,0\n",
+		"from,to,rate\nCAD,USD,-0.8\n",
+		"from,to,rate\nCAD,USD,abc\n",
+		"from,to,rate,effective_at\nCAD,USD,0.8,05/01/2024\n",
+	}
+	for _, input := range csvCases {
+		if _, err := ReadRatesCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error reading CSV %q", input)
+		}
+	}
+	jsonCases := []string{
+		"",
+		"{}",
+		`[{"from":"usd","to":"CAD","rate":1.25}]`,
+		`[{"from":"USD","to":"CAD","rate":0}]`,
+		`[{"from":"USD","to":"CAD","rate":"1e3"}]`,
+		`[{"from":"USD","to":"CAD"}]`,
+	}
+	for _, input := range jsonCases {
+		if _, err := ReadRatesJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error reading JSON %q", input)
+		}
+	}
+}
+
+func TestReadRatesCSVReportsLine(t *testing.T) {
+	_, err := ReadRatesCSV(strings.NewReader("from,to,rate\nCAD,USD,0.8\nCNY,USD,0\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("expected an error on line 3 but got %v", err)
+	}
+}
+
+func TestLoadRatesFile(t *testing.T) {
+	for _, path := range []string{"testdata/rates.csv", "testdata/rates.json"} {
+		t.Run(path, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			rates, err := LoadRatesFile(path)
+			is.NoErr(err)
+			rate, err := rates.Rate(context.Background(), CAD, USD)
+			is.NoErr(err)
+			is.Equal(rate, Rate{From: CAD, To: USD, Units: 0, Nanos: 800000000, EffectiveAt: date("2024-01-05")}) // the latest rate wins
+			_, err = rates.Rate(context.Background(), "GBP", EUR)
+			is.NoErr(err)
+		})
+	}
+	if _, err := LoadRatesFile("testdata/rates.xml"); err == nil {
+		t.Errorf("expected an error loading an unsupported file type")
+	}
+}
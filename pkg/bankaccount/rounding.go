@@ -0,0 +1,80 @@
+package bankaccount
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode determines how a result is rounded when it cannot be represented exactly, e.g., when
+// multiplying by a fraction would leave fractional nanos. The zero value is RoundHalfUp.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value, rounding ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value, rounding ties to the nearest even value (banker's rounding).
+	RoundHalfEven
+	// RoundHalfDown rounds to the nearest value, rounding ties towards zero.
+	RoundHalfDown
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundTowardZero truncates any fraction.
+	RoundTowardZero
+	// RoundAwayFromZero rounds any fraction away from zero.
+	RoundAwayFromZero
+)
+
+func (r RoundingMode) String() string {
+	switch r {
+	case RoundHalfUp:
+		return "half-up"
+	case RoundHalfEven:
+		return "half-even"
+	case RoundHalfDown:
+		return "half-down"
+	case RoundCeiling:
+		return "ceiling"
+	case RoundFloor:
+		return "floor"
+	case RoundTowardZero:
+		return "toward-zero"
+	case RoundAwayFromZero:
+		return "away-from-zero"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(r))
+}
+
+// quo returns n / d rounded according to the rounding mode.
+func (r RoundingMode) quo(n *big.Int, d *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(n, d, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	// the sign of the exact result, which is also the direction to move in to round away from zero
+	sign := n.Sign() * d.Sign()
+	var awayFromZero bool
+	switch r {
+	case RoundCeiling:
+		awayFromZero = sign > 0
+	case RoundFloor:
+		awayFromZero = sign < 0
+	case RoundTowardZero:
+		awayFromZero = false
+	case RoundAwayFromZero:
+		awayFromZero = true
+	default:
+		twiceRemainder := remainder.Abs(remainder).Lsh(remainder, 1)
+		switch twiceRemainder.Cmp(new(big.Int).Abs(d)) {
+		case 1:
+			awayFromZero = true
+		case 0:
+			awayFromZero = r == RoundHalfUp || (r == RoundHalfEven && quotient.Bit(0) == 1)
+		}
+	}
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
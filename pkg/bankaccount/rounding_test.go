@@ -0,0 +1,75 @@
+package bankaccount
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type roundingTestCase struct {
+	money    Money
+	mantissa int
+	exponent int
+	mode     RoundingMode
+	expected Money
+}
+
+func (tc roundingTestCase) Name(i int) string {
+	return fmt.Sprintf("Example %d: %s%d.%d * %dx10^%d %s",
+		i, tc.money.CurrencyCode, tc.money.Units, tc.money.Nanos, tc.mantissa, tc.exponent, tc.mode)
+}
+
+func (tc roundingTestCase) Run(t *testing.T) {
+	is := is.NewRelaxed(t)
+	actual, err := tc.money.MultiplyWithRounding(tc.mantissa, tc.exponent, tc.mode)
+	is.NoErr(err)
+	is.Equal(actual.CurrencyCode, tc.expected.CurrencyCode) // CurrencyCode
+	is.Equal(actual.Units, tc.expected.Units)               // Units
+	is.Equal(actual.Nanos, tc.expected.Nanos)               // Nanos
+}
+
+func TestMultiplyWithRounding(t *testing.T) {
+	// each input is multiplied by 0.5, so odd nanos always leave a tie, i.e., x.5 nanos
+	inputs := []int32{5, 3, 1, -1, -3, -5}
+	expected := map[RoundingMode][]int32{
+		RoundHalfUp:       {3, 2, 1, -1, -2, -3},
+		RoundHalfEven:     {2, 2, 0, 0, -2, -2},
+		RoundHalfDown:     {2, 1, 0, 0, -1, -2},
+		RoundCeiling:      {3, 2, 1, 0, -1, -2},
+		RoundFloor:        {2, 1, 0, -1, -2, -3},
+		RoundTowardZero:   {2, 1, 0, 0, -1, -2},
+		RoundAwayFromZero: {3, 2, 1, -1, -2, -3},
+	}
+	testCases := []roundingTestCase{}
+	for mode, results := range expected {
+		for i, nanos := range inputs {
+			testCases = append(testCases, roundingTestCase{Money{USD, 0, nanos}, 5, -1, mode, Money{USD, 0, results[i]}})
+		}
+	}
+	// fractions other than a tie
+	testCases = append(testCases,
+		roundingTestCase{Money{USD, 0, 1}, 6, -1, RoundHalfDown, Money{USD, 0, 1}},
+		roundingTestCase{Money{USD, 0, 1}, 6, -1, RoundHalfEven, Money{USD, 0, 1}},
+		roundingTestCase{Money{USD, 0, -1}, 6, -1, RoundHalfDown, Money{USD, 0, -1}},
+		roundingTestCase{Money{USD, 0, 1}, 4, -1, RoundHalfUp, Money{USD, 0, 0}},
+		roundingTestCase{Money{USD, 0, 1}, 1, -1, RoundCeiling, Money{USD, 0, 1}},
+		roundingTestCase{Money{USD, 0, -1}, 1, -1, RoundFloor, Money{USD, 0, -1}},
+		roundingTestCase{Money{USD, 0, 1}, 1, -1, RoundAwayFromZero, Money{USD, 0, 1}},
+		roundingTestCase{Money{USD, 0, 1}, 9, -1, RoundTowardZero, Money{USD, 0, 0}},
+		roundingTestCase{Money{USD, 0, 1}, -5, -1, RoundCeiling, Money{USD, 0, 0}},
+		roundingTestCase{Money{USD, 0, 1}, -5, -1, RoundFloor, Money{USD, 0, -1}},
+		roundingTestCase{Money{USD, 1, 999999999}, 5, -1, RoundHalfUp, Money{USD, 1, 0}},
+		roundingTestCase{Money{USD, 1, 999999999}, 5, -1, RoundHalfEven, Money{USD, 1, 0}},
+		roundingTestCase{Money{USD, 1, 999999997}, 5, -1, RoundHalfEven, Money{USD, 0, 999999998}},
+	)
+	for i, tc := range testCases {
+		t.Run(tc.Name(i), tc.Run)
+	}
+}
+
+func TestRoundingModeString(t *testing.T) {
+	is := is.New(t)
+	is.Equal(RoundHalfEven.String(), "half-even")
+	is.Equal(RoundingMode(42).String(), "RoundingMode(42)")
+}
@@ -0,0 +1,202 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+var accountSeq uint64
+
+// nextAccountSeq returns a number that is unique to each account, which orders the locks taken by a transfer.
+func nextAccountSeq() uint64 {
+	return atomic.AddUint64(&accountSeq, 1)
+}
+
+// transferable is implemented by the accounts that can take part in a Transfer. A transfer locks both accounts,
+// prepares the transactions on each without changing anything, and only commits them once both are known to
+// succeed.
+type transferable interface {
+	Account
+	sequence() uint64
+	lock()
+	unlock()
+	// creditCurrency returns the currency that an amount in the given currency is credited to the account in.
+	creditCurrency(currencyCode string) string
+	transferRates() (ExchangeRateProvider, RoundingMode)
+	ledgerAccount() (*Journal, LedgerAccount)
+	// prepareDebit and prepareCredit return the balance that would result from the transaction. The caller must
+	// hold the lock.
+	prepareDebit(t Transaction) (Money, error)
+	prepareCredit(t Transaction) (Money, error)
+	// commit applies a prepared transaction and records it in the ledger. The caller must hold the lock.
+	commit(t Transaction, opts []TransactionOption)
+}
+
+// Transfer atomically moves an amount from one account to another: either both accounts change, or neither
+// does. The amount must be positive and in a currency the from account holds. If the to account holds a
+// different currency, the amount is converted using the to account's rate provider and rounding mode, rounded
+// to the minor unit of that currency. The accounts are locked in a consistent order, so concurrent transfers in
+// opposite directions cannot deadlock. Both accounts must have been created by this package.
+func Transfer(ctx context.Context, from Account, to Account, amount Money, opts ...TransactionOption) error {
+	source, ok := from.(transferable)
+	if !ok {
+		return fmt.Errorf("cannot transfer from an account of type %T", from)
+	}
+	dest, ok := to.(transferable)
+	if !ok {
+		return fmt.Errorf("cannot transfer to an account of type %T", to)
+	}
+	if source.sequence() == dest.sequence() {
+		return fmt.Errorf("cannot transfer from an account to itself")
+	}
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+	if !amount.IsPositive() {
+		return fmt.Errorf("transfer of %s must be positive", amount)
+	}
+
+	credited := amount
+	var conversion *Conversion
+	if currencyCode := dest.creditCurrency(amount.CurrencyCode); currencyCode != amount.CurrencyCode {
+		rates, mode := dest.transferRates()
+		rate, err := rates.Rate(ctx, amount.CurrencyCode, currencyCode)
+		if err != nil {
+			return fmt.Errorf("cannot convert %s to %s: %w", amount, currencyCode, err)
+		}
+		if credited, err = rate.convertToMinorUnits(amount, mode); err != nil {
+			return err
+		}
+		conversion = &Conversion{Original: amount, Rate: rate, Converted: credited}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	first, second := source, dest
+	if second.sequence() < first.sequence() {
+		first, second = second, first
+	}
+	first.lock()
+	defer first.unlock()
+	second.lock()
+	defer second.unlock()
+
+	var err error
+	out := Transaction{Type: TransactionTransferOut, Amount: amount, Conversion: conversion}
+	if out.Balance, err = source.prepareDebit(out); err != nil {
+		return err
+	}
+	in := Transaction{Type: TransactionTransferIn, Amount: credited, Conversion: conversion}
+	if in.Balance, err = dest.prepareCredit(in); err != nil {
+		return err
+	}
+	if err := postTransfer(source, dest, amount, conversion); err != nil {
+		return err
+	}
+	source.commit(out, opts)
+	dest.commit(in, opts)
+	return nil
+}
+
+// postTransfer posts a transfer to the accounts' journals. If both accounts use the same journal, a single entry
+// moves the money between the customers' ledger accounts. Otherwise, the money leaves one journal and enters the
+// other as cash, and if the second entry fails, the first is reversed.
+func postTransfer(source transferable, dest transferable, amount Money, conversion *Conversion) error {
+	memo := fmt.Sprintf("transfer of %s", amount)
+	fromJournal, fromCustomer := source.ledgerAccount()
+	toJournal, toCustomer := dest.ledgerAccount()
+	credited := amount
+	if conversion != nil {
+		credited = conversion.Converted
+	}
+	if fromJournal == toJournal {
+		return postTo(fromJournal, memo, transferPostings(fromCustomer, toCustomer, credited, conversion))
+	}
+	outgoing := withdrawalPostings(fromCustomer, amount, nil)
+	if err := postTo(fromJournal, memo, outgoing); err != nil {
+		return err
+	}
+	if err := postTo(toJournal, memo, depositPostings(toCustomer, credited, conversion)); err != nil {
+		if reverseErr := postTo(fromJournal, "reversal of "+memo, reversal(outgoing)); reverseErr != nil {
+			return fmt.Errorf("%w; reversing the transfer: %v", err, reverseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SavingsAccount) sequence() uint64 {
+	return s.seq
+}
+
+func (s *SavingsAccount) lock() {
+	s.mu.Lock()
+}
+
+func (s *SavingsAccount) unlock() {
+	s.mu.Unlock()
+}
+
+func (s *SavingsAccount) creditCurrency(string) string {
+	return s.Balance().CurrencyCode
+}
+
+func (s *SavingsAccount) transferRates() (ExchangeRateProvider, RoundingMode) {
+	return s.rates, s.rounding
+}
+
+func (s *SavingsAccount) ledgerAccount() (*Journal, LedgerAccount) {
+	return s.journal, s.customer
+}
+
+func (s *SavingsAccount) prepareDebit(t Transaction) (Money, error) {
+	return s.debitedBalance(t)
+}
+
+func (s *SavingsAccount) prepareCredit(t Transaction) (Money, error) {
+	return s.balance.Add(t.Amount)
+}
+
+func (s *SavingsAccount) commit(t Transaction, opts []TransactionOption) {
+	s.balance = t.Balance
+	s.ledger.append(opts, t)
+}
+
+func (a *MultiCurrencyAccount) sequence() uint64 {
+	return a.seq
+}
+
+func (a *MultiCurrencyAccount) lock() {
+	a.mu.Lock()
+}
+
+func (a *MultiCurrencyAccount) unlock() {
+	a.mu.Unlock()
+}
+
+func (a *MultiCurrencyAccount) creditCurrency(currencyCode string) string {
+	return currencyCode
+}
+
+func (a *MultiCurrencyAccount) transferRates() (ExchangeRateProvider, RoundingMode) {
+	return a.rates, a.rounding
+}
+
+func (a *MultiCurrencyAccount) ledgerAccount() (*Journal, LedgerAccount) {
+	return a.journal, a.customer
+}
+
+func (a *MultiCurrencyAccount) prepareDebit(t Transaction) (Money, error) {
+	return a.debitedBalance(t.Type.String(), t.Amount)
+}
+
+func (a *MultiCurrencyAccount) prepareCredit(t Transaction) (Money, error) {
+	return a.balanceLocked(t.Amount.CurrencyCode).Add(t.Amount)
+}
+
+func (a *MultiCurrencyAccount) commit(t Transaction, opts []TransactionOption) {
+	a.setBalanceLocked(t.Balance)
+	a.ledger.append(opts, t)
+}
@@ -0,0 +1,183 @@
+package bankaccount
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTransfer(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	j := NewJournal()
+	alice := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}), WithJournal(j, "alice"))
+	bob := mustNewSavingsAccount(t, WithBalance(Money{USD, 10, 0}), WithJournal(j, "bob"))
+
+	is.NoErr(Transfer(ctx, alice, bob, Money{USD, 30, 0}, WithMemo("dinner")))
+	is.Equal(alice.Balance(), Money{USD, 70, 0})
+	is.Equal(bob.Balance(), Money{USD, 40, 0})
+	out := alice.Transactions(TransactionFilter{})[1]
+	is.Equal(out.Type, TransactionTransferOut)
+	is.Equal(out.Balance, Money{USD, 70, 0})
+	is.Equal(out.Memo, "dinner")
+	in := bob.Transactions(TransactionFilter{})[1]
+	is.Equal(in.Type, TransactionTransferIn)
+	is.Equal(in.Balance, Money{USD, 40, 0})
+
+	entries := j.Entries()
+	is.Equal(entries[len(entries)-1].Postings, []Posting{
+		Debit(CustomerLedgerAccount("alice"), Money{USD, 30, 0}),
+		Credit(CustomerLedgerAccount("bob"), Money{USD, 30, 0}),
+	})
+
+	// a transfer that fails changes neither account
+	is.True(Transfer(ctx, bob, alice, Money{USD, 41, 0}) != nil)
+	is.Equal(alice.Balance(), Money{USD, 70, 0})
+	is.Equal(bob.Balance(), Money{USD, 40, 0})
+	is.Equal(len(alice.Transactions(TransactionFilter{})), 2)
+	is.Equal(len(bob.Transactions(TransactionFilter{})), 2)
+	is.Equal(len(j.Entries()), len(entries))
+}
+
+func TestTransferBetweenCurrencies(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	usd := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}))
+	cad := mustNewSavingsAccount(t, WithBalance(Money{CAD, 0, 0}))
+	wallet := mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{EUR, 10, 0}))
+
+	is.NoErr(Transfer(ctx, usd, cad, Money{USD, 40, 0}))
+	is.Equal(usd.Balance(), Money{USD, 60, 0})
+	is.Equal(cad.Balance(), Money{CAD, 50, 0}) // at the inverse of CAD/USD 0.8
+	is.Equal(*cad.Transactions(TransactionFilter{})[0].Conversion, Conversion{
+		Original:  Money{USD, 40, 0},
+		Rate:      Rate{From: USD, To: CAD, Units: 1, Nanos: 250000000, Path: []Rate{{From: CAD, To: USD, Nanos: 800000000}}},
+		Converted: Money{CAD, 50, 0},
+	})
+
+	// a multi-currency account keeps what it receives in the same currency
+	is.NoErr(Transfer(ctx, cad, wallet, Money{CAD, 20, 0}))
+	is.Equal(wallet.Balances(), []Money{{CAD, 20, 0}, {EUR, 10, 0}})
+	is.NoErr(Transfer(ctx, wallet, usd, Money{EUR, 10, 0}))
+	is.Equal(usd.Balance(), Money{USD, 70, 800000000})
+	is.Equal(wallet.Balances(), []Money{{CAD, 20, 0}})
+
+	is.True(Transfer(ctx, wallet, usd, Money{EUR, 1, 0}) != nil) // no longer holds any EUR
+	is.True(Transfer(ctx, usd, cad, Money{CAD, 1, 0}) != nil)    // not in the from account's currency
+	jpy := mustNewSavingsAccount(t, WithBalance(Money{"JPY", 0, 0}))
+	is.True(Transfer(ctx, usd, jpy, Money{USD, 1, 0}) != nil) // no rate
+	is.Equal(usd.Balance(), Money{USD, 70, 800000000})
+
+	// USD 0.01499999997 is rounded once to the cent, not to USD 0.015000000 and then up to USD 0.02
+	rates := NewExchangeRates(Rate{From: CAD, To: USD, Nanos: 499999999})
+	cad = mustNewSavingsAccount(t, WithBalance(Money{CAD, 1, 0}))
+	usd = mustNewSavingsAccount(t, WithRateProvider(rates))
+	is.NoErr(Transfer(ctx, cad, usd, Money{CAD, 0, 30000000}))
+	is.Equal(usd.Balance(), Money{USD, 0, 10000000})
+}
+
+func TestTransferJournals(t *testing.T) {
+	is := is.New(t)
+	bank, other := NewJournal(), NewJournal()
+	alice := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}), WithJournal(bank, "alice"))
+	bob := mustNewSavingsAccount(t, WithBalance(Money{CAD, 0, 0}), WithJournal(other, "bob"))
+	carol := mustNewSavingsAccount(t, WithBalance(Money{USD, 0, 0}))
+
+	is.NoErr(Transfer(context.Background(), alice, bob, Money{USD, 40, 0}))
+	is.NoErr(Transfer(context.Background(), alice, carol, Money{USD, 10, 0}))
+	for _, j := range []*Journal{bank, other} {
+		_, err := j.TrialBalance()
+		is.NoErr(err)
+	}
+	owed, err := bank.Balance(CustomerLedgerAccount("alice"), USD)
+	is.NoErr(err)
+	is.Equal(owed, Money{USD, -50, 0})
+	owed, err = other.Balance(CustomerLedgerAccount("bob"), CAD)
+	is.NoErr(err)
+	is.Equal(owed, Money{CAD, -50, 0})
+	cash, err := bank.Balance(LedgerCash, USD)
+	is.NoErr(err)
+	is.Equal(cash, Money{USD, 50, 0}) // the money left the bank's books
+}
+
+type otherAccount struct {
+	Account
+}
+
+func TestTransferErrors(t *testing.T) {
+	alice := mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}))
+	bob := mustNewSavingsAccount(t)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	testCases := []struct {
+		ctx    context.Context
+		from   Account
+		to     Account
+		amount Money
+	}{
+		{context.Background(), alice, alice, Money{USD, 1, 0}},
+		{context.Background(), alice, bob, Money{USD, 0, 0}},
+		{context.Background(), alice, bob, Money{USD, -1, 0}},
+		{context.Background(), alice, bob, Money{USD, 1, -1}},
+		{context.Background(), otherAccount{alice}, bob, Money{USD, 1, 0}},
+		{context.Background(), alice, otherAccount{bob}, Money{USD, 1, 0}},
+		{canceled, alice, bob, Money{USD, 1, 0}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Example %d", i), func(t *testing.T) {
+			if err := Transfer(tc.ctx, tc.from, tc.to, tc.amount); err == nil {
+				t.Errorf("expected an error transferring %s", tc.amount)
+			}
+		})
+	}
+	if alice.Balance() != (Money{USD, 100, 0}) || bob.Balance() != (Money{USD, 0, 0}) {
+		t.Errorf("expected failed transfers to leave the balances unchanged")
+	}
+}
+
+func TestConcurrentTransfers(t *testing.T) {
+	is := is.New(t)
+	j := NewJournal()
+	accounts := make([]Account, 10)
+	for i := range accounts {
+		if i%2 == 0 {
+			accounts[i] = mustNewSavingsAccount(t, WithBalance(Money{USD, 100, 0}), WithJournal(j, fmt.Sprint(i)))
+		} else {
+			accounts[i] = mustNewMultiCurrencyAccount(t, WithOpeningBalances(Money{USD, 100, 0}), WithMultiCurrencyJournal(j, fmt.Sprint(i)))
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < 20; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for n := 0; n < 200; n++ {
+				from, to := accounts[r.Intn(len(accounts))], accounts[r.Intn(len(accounts))]
+				// many transfers fail by overdrawing or by transferring to the same account, which is fine
+				Transfer(context.Background(), from, to, Money{USD, int64(r.Intn(50)), int32(r.Intn(100)) * 10000000})
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+
+	total := Money{USD, 0, 0}
+	for _, acct := range accounts {
+		balance := acct.Balance()
+		is.True(!balance.IsNegative()) // no account is overdrawn
+		var err error
+		total, err = total.Add(balance)
+		is.NoErr(err)
+	}
+	is.Equal(total, Money{USD, 1000, 0}) // no money was created or lost
+	_, err := j.TrialBalance()
+	is.NoErr(err)
+	cash, err := j.Balance(LedgerCash, USD)
+	is.NoErr(err)
+	is.Equal(cash, Money{USD, 1000, 0})
+}